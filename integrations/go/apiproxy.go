@@ -0,0 +1,72 @@
+package ora
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// apiProxy forwards the requests under its prefix to the backend REST API,
+// so the admin and the API it drives share one origin
+type apiProxy struct {
+	prefix   string
+	proxy    http.Handler
+	readOnly bool
+	audit    *auditor
+}
+
+func newAPIProxy(o *options) *apiProxy {
+	prefix := path.Clean("/" + o.config.ApiProxyPath)
+	target, _ := url.Parse(o.config.ApiProxyTarget) // checked by validate
+	rp := &httputil.ReverseProxy{
+		// headers, Authorization included, are forwarded as is, hop-by-hop ones are dropped by ReverseProxy
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+			if id := RequestIDFromContext(r.In.Context()); id != "" {
+				r.Out.Header.Set(requestIDHeader, id)
+			}
+		},
+		Transport: o.client.Transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			o.logger.ErrorContext(r.Context(), "ora: api proxy failed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("target", o.config.ApiProxyTarget),
+				slog.Any("error", err),
+			)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	return &apiProxy{
+		prefix:   prefix,
+		proxy:    http.StripPrefix(prefix, rp),
+		readOnly: o.config.ReadOnly,
+		audit:    &auditor{logger: o.logger, hooks: o.auditHooks},
+	}
+}
+
+// match reports whether the cleaned path p is served by the proxy
+func (p *apiProxy) match(path string) bool {
+	return path == p.prefix || strings.HasPrefix(path, p.prefix+"/")
+}
+
+func (p *apiProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.audit.wrap(w, r, p.serve)
+}
+
+func (p *apiProxy) serve(w http.ResponseWriter, r *http.Request) {
+	if p.readOnly && !isSafeMethod(r.Method) {
+		http.Error(w, "the admin is read-only", http.StatusForbidden)
+		return
+	}
+
+	p.proxy.ServeHTTP(w, r)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
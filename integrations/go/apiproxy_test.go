@@ -0,0 +1,41 @@
+package ora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadOnlyProxyRejectsMutations(t *testing.T) {
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer upstream.Close()
+
+	h := NewWithConfig(&Config{
+		OpenapiDocUrl:  "/openapi.json",
+		ApiProxyTarget: upstream.URL,
+		ApiProxyPath:   "/api",
+		ReadOnly:       true,
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/api/users/1", nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s /api/users/1 = %d, want 403", method, w.Code)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("upstream called %d times for rejected mutations", n)
+	}
+
+	if w := get(h, "/api/users/1"); w.Code != http.StatusOK {
+		t.Errorf("GET /api/users/1 = %d, want 200", w.Code)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream called %d times, want the GET once", n)
+	}
+}
@@ -0,0 +1,189 @@
+package ora
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	cacheNoCache   = "no-cache"
+	cacheImmutable = "public, max-age=31536000, immutable"
+)
+
+// hashedName matches the content hashed file names vite emits under assets/, e.g. assets/index-BkVlZ2xA.js
+var hashedName = regexp.MustCompile(`^assets/.+-([A-Za-z0-9_-]{8})\.[a-z0-9]+$`)
+
+// isHashed reports whether name is a vite build output, whose content never changes under it.
+// A hash of lower case letters only is taken for a word, like assets/custom-branding.css,
+// those are rare enough in vite hashes for the odd one to just be revalidated.
+func isHashed(name string) bool {
+	m := hashedName.FindStringSubmatch(name)
+	return m != nil && strings.ContainsAny(m[1], "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ_-")
+}
+
+// asset is a file served from memory, compressed and hashed once at construction
+type asset struct {
+	contentType  string
+	cacheControl string
+	etag         string
+	body         []byte
+	encoded      []encodedBody
+}
+
+// encodedBody is a content coding of an asset body, assets keep them in order of preference
+type encodedBody struct {
+	coding string
+	body   []byte
+}
+
+// encoder compresses bodies into a content coding, like gzip or br.
+// encode is for the bodies compressed once at construction, encodeFast for those compressed
+// on every response, like the shell carrying a nonce, at a level that is cheap enough for it.
+type encoder struct {
+	coding     string
+	encode     func([]byte) ([]byte, error)
+	encodeFast func([]byte) ([]byte, error)
+}
+
+var gzipEncoder = encoder{coding: "gzip", encode: gzipLevel(gzip.BestCompression), encodeFast: gzipLevel(gzip.DefaultCompression)}
+
+func gzipLevel(level int) func([]byte) ([]byte, error) {
+	return func(body []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, level)
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+func newAsset(contentType, cacheControl string, body []byte, encoders []encoder) (*asset, error) {
+	a := &asset{contentType: contentType, cacheControl: cacheControl, etag: etagOf(body), body: body}
+	for _, e := range encoders {
+		data, err := e.encode(body)
+		if err != nil {
+			return nil, fmt.Errorf("ora: %s encode: %w", e.coding, err)
+		}
+
+		// already compressed formats like images don't shrink, keep them plain
+		if len(data) < len(body) {
+			a.encoded = append(a.encoded, encodedBody{coding: e.coding, body: data})
+		}
+	}
+	return a, nil
+}
+
+// loadAssets reads every file of fsys except the index, which is rendered separately
+func loadAssets(fsys fs.FS, encoders []encoder) (map[string]*asset, error) {
+	assets := make(map[string]*asset)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || name == indexName {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			ctype = http.DetectContentType(data)
+		}
+
+		cacheControl := cacheNoCache
+		if isHashed(name) {
+			cacheControl = cacheImmutable
+		}
+
+		assets[name], err = newAsset(ctype, cacheControl, data, encoders)
+		return err
+	})
+	return assets, err
+}
+
+func (a *asset) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, etag := a.body, a.etag
+	if len(a.encoded) > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+		for _, e := range a.encoded {
+			if acceptsEncoding(r, e.coding) {
+				w.Header().Set("Content-Encoding", e.coding)
+				body, etag = e.body, strings.TrimSuffix(etag, `"`)+"-"+e.coding+`"`
+				break
+			}
+		}
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", a.cacheControl)
+	if etagMatch(r, etag) {
+		w.Header().Del("Content-Encoding")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.contentType)
+	writeBody(w, r, body)
+}
+
+// writeBody writes body with its Content-Length, HEAD requests only get the headers
+func writeBody(w http.ResponseWriter, r *http.Request, body []byte) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// acceptsEncoding reports whether the Accept-Encoding of r allows coding
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if !strings.EqualFold(strings.TrimSpace(name), coding) {
+				continue
+			}
+
+			q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !found {
+				return true
+			}
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+	}
+	return false
+}
+
+func etagOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatch reports whether the If-None-Match of r matches etag, using the weak comparison
+func etagMatch(r *http.Request, etag string) bool {
+	for _, v := range r.Header.Values("If-None-Match") {
+		for _, candidate := range strings.Split(v, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package ora
+
+import "testing"
+
+func TestIsHashed(t *testing.T) {
+	for name, want := range map[string]bool{
+		"assets/index-BkVlZ2xA.js":      true,
+		"assets/vendor-a1b2c3d4.css":    true,
+		"assets/chunk-x_Y-9zQw.js":      true,
+		"assets/sub/icons-AbCdEf12.svg": true,
+		"index-BkVlZ2xA.js":             false,
+		"apple-touch-icon.png":          false,
+		"company-logo-large.png":        false,
+		"custom-branding.css":           false,
+		"assets/custom-branding.css":    false,
+		"assets/logo-large.png":         false,
+		"assets/index-BkVlZ2xAxx.js":    false,
+		"assets/index.js":               false,
+	} {
+		if got := isHashed(name); got != want {
+			t.Errorf("isHashed(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
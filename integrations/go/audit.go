@@ -0,0 +1,67 @@
+package ora
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AuditEvent records a mutating request that went through the API proxy
+//
+// Only the fields below are recorded, headers such as Authorization never are.
+type AuditEvent struct {
+	Time      time.Time
+	RequestID string
+	// Subject is the sub claim validated by AuthMiddleware, empty without it
+	Subject string
+	Method  string
+	Path    string
+	// Status is the status returned by the upstream, or 403 when ReadOnly rejected the request
+	Status int
+}
+
+// auditor emits an AuditEvent for every mutating request through the logger and the hooks
+type auditor struct {
+	logger *slog.Logger
+	hooks  []func(AuditEvent)
+}
+
+// wrap serves r with next, auditing it when it isn't a safe method
+func (a *auditor) wrap(w http.ResponseWriter, r *http.Request, next func(http.ResponseWriter, *http.Request)) {
+	if isSafeMethod(r.Method) {
+		next(w, r)
+		return
+	}
+
+	// without RequestIDMiddleware mutations still get an ID, set on the request so it is forwarded upstream
+	requestID := RequestIDFromContext(r.Context())
+	if requestID == "" {
+		requestID = r.Header.Get(requestIDHeader)
+	}
+	if !validRequestID(requestID) {
+		requestID = newRequestID()
+		r.Header.Set(requestIDHeader, requestID)
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	next(rec, r)
+
+	event := AuditEvent{
+		Time:      time.Now(),
+		RequestID: requestID,
+		Subject:   ClaimsFromContext(r.Context()).Subject(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    rec.status,
+	}
+	a.logger.InfoContext(r.Context(), "ora: audit",
+		slog.String("request_id", event.RequestID),
+		slog.String("subject", event.Subject),
+		slog.String("method", event.Method),
+		slog.String("path", event.Path),
+		slog.Int("status", event.Status),
+	)
+	for _, hook := range a.hooks {
+		hook(event)
+	}
+}
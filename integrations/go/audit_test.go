@@ -0,0 +1,73 @@
+package ora
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuditHook(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	var events []AuditEvent
+	hook := WithAuditHook(func(e AuditEvent) { events = append(events, e) })
+	cfg := &Config{OpenapiDocUrl: "/openapi.json", ApiProxyTarget: upstream.URL, ApiProxyPath: "/api"}
+
+	send := func(h http.Handler, method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/users?token=secret-query", nil)
+		r.Header.Set("Authorization", "Bearer secret-token")
+		r.Header.Set(requestIDHeader, "req-1")
+		r = r.WithContext(context.WithValue(r.Context(), claimsKey{}, Claims{"sub": "alice"}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	h := NewWithConfig(cfg, hook, WithLogger(logger))
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		send(h, method)
+	}
+	if len(events) != 0 {
+		t.Errorf("safe methods audited: %+v", events)
+	}
+
+	if w := send(h, http.MethodPost); w.Code != http.StatusCreated {
+		t.Fatalf("POST /api/users = %d, want 201", w.Code)
+	}
+	if len(events) != 1 {
+		t.Fatalf("POST audited %d times, want once", len(events))
+	}
+	want := AuditEvent{Time: events[0].Time, RequestID: "req-1", Subject: "alice", Method: http.MethodPost, Path: "/api/users", Status: http.StatusCreated}
+	if events[0] != want || events[0].Time.IsZero() {
+		t.Errorf("audit event = %+v, want %+v", events[0], want)
+	}
+
+	events = nil
+	readOnly := *cfg
+	readOnly.ReadOnly = true
+	if w := send(NewWithConfig(&readOnly, hook, WithLogger(logger)), http.MethodDelete); w.Code != http.StatusForbidden {
+		t.Fatalf("read-only DELETE = %d, want 403", w.Code)
+	}
+	if len(events) != 1 || events[0].Status != http.StatusForbidden || events[0].Method != http.MethodDelete {
+		t.Errorf("read-only DELETE audited as %+v, want one 403 event", events)
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "ora: audit") {
+		t.Errorf("no audit record logged in %q", out)
+	}
+	for _, secret := range []string{"secret-token", "secret-query"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("logs leak %s: %q", secret, out)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package ora
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway given to exp and nbf
+const clockSkew = time.Minute
+
+// Claims are the claims of a validated token
+type Claims map[string]any
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims AuthMiddleware validated for the request, or nil
+func ClaimsFromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// AuthMiddleware rejects requests without a valid "Authorization: Bearer" token issued by cfg.OidcIssuer
+//
+// Tokens are verified against the keys published at the jwks_uri of the issuer's discovery document,
+// their exp and nbf are checked, and so is aud when cfg.OidcAudience is set.
+// The validated claims are available through ClaimsFromContext.
+// It panics if cfg.OidcIssuer is empty.
+func AuthMiddleware(cfg *Config, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(cfg, opts)
+	if o.config.OidcIssuer == "" {
+		panic("ora: AuthMiddleware requires OidcIssuer")
+	}
+
+	v := &verifier{
+		issuer:   o.config.OidcIssuer,
+		audience: o.config.OidcAudience,
+		keys:     &keySet{issuer: o.config.OidcIssuer, client: o.client, timeout: o.fetchTimeout},
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := v.verify(r.Context(), token)
+			if errors.Is(err, errKeysUnavailable) {
+				// the token may well be valid, the issuer is what failed
+				upstreamError(w, err, "failed to fetch the issuer's signing keys")
+				return
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+type verifier struct {
+	issuer   string
+	audience string
+	keys     *keySet
+}
+
+func (v *verifier) verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("ora: malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := v.keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, v.validate(claims)
+}
+
+func (v *verifier) validate(claims Claims) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("ora: token has no exp")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return errors.New("ora: token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("ora: token is not valid yet")
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimRight(iss, "/") != strings.TrimRight(v.issuer, "/") {
+		return fmt.Errorf("ora: token issued by %q", iss)
+	}
+
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return errors.New("ora: token audience mismatch")
+	}
+	return nil
+}
+
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("ora: unsupported alg %q", alg)
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("ora: unsupported alg %q", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, hash, digest, sig)
+		case "PS":
+			return rsa.VerifyPSS(key, hash, digest, sig, nil)
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if alg[:2] == "ES" && len(sig) == 2*size {
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return nil
+			}
+			return errors.New("ora: invalid signature")
+		}
+	}
+	return fmt.Errorf("ora: alg %q doesn't match the key", alg)
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,145 @@
+package ora
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sign issues a token for claims, signed by the key of iss under kid with alg
+func (iss *issuer) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	segment := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := segment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch alg {
+	case "RS256":
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, iss.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, iss.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "HS256":
+		// the public modulus used as an HMAC secret, the classic key confusion
+		mac := hmac.New(sha256.New, iss.rsaKey.N.Bytes())
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	iss := newIssuer(t)
+	var got Claims
+	h := AuthMiddleware(&Config{OidcIssuer: iss.URL, OidcAudience: "admin"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClaimsFromContext(r.Context())
+	}))
+
+	now := time.Now()
+	claims := func(edit func(map[string]any)) map[string]any {
+		c := map[string]any{"iss": iss.URL, "aud": "admin", "sub": "alice", "exp": now.Add(time.Hour).Unix()}
+		if edit != nil {
+			edit(c)
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"RS256", iss.sign(t, "RS256", "k1", claims(nil)), http.StatusOK},
+		{"ES256", iss.sign(t, "ES256", "k2", claims(nil)), http.StatusOK},
+		{"audience list", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { c["aud"] = []string{"other", "admin"} })), http.StatusOK},
+		{"expired", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { c["exp"] = now.Add(-time.Hour).Unix() })), http.StatusUnauthorized},
+		{"no exp", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { delete(c, "exp") })), http.StatusUnauthorized},
+		{"future nbf", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { c["nbf"] = now.Add(time.Hour).Unix() })), http.StatusUnauthorized},
+		{"wrong iss", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { c["iss"] = "https://evil.example.com" })), http.StatusUnauthorized},
+		{"wrong aud", iss.sign(t, "RS256", "k1", claims(func(c map[string]any) { c["aud"] = "other" })), http.StatusUnauthorized},
+		{"alg none", iss.sign(t, "none", "k1", claims(nil)), http.StatusUnauthorized},
+		{"HS256 with the RSA modulus", iss.sign(t, "HS256", "k1", claims(nil)), http.StatusUnauthorized},
+		{"RS256 claimed for the EC key", iss.sign(t, "RS256", "k2", claims(nil)), http.StatusUnauthorized},
+		{"unknown kid", iss.sign(t, "RS256", "k3", claims(nil)), http.StatusUnauthorized},
+		{"malformed", "not-a-token", http.StatusUnauthorized},
+	} {
+		got = nil
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+tc.token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+			continue
+		}
+		if tc.want == http.StatusOK && got.Subject() != "alice" {
+			t.Errorf("%s: ClaimsFromContext = %v, want the token's claims", tc.name, got)
+		}
+		if tc.want == http.StatusUnauthorized && (got != nil || w.Header().Get("WWW-Authenticate") != `Bearer error="invalid_token"`) {
+			t.Errorf("%s: rejected with WWW-Authenticate %q, reached next = %v", tc.name, w.Header().Get("WWW-Authenticate"), got != nil)
+		}
+	}
+
+	for _, authorization := range []string{"", "Basic YWxpY2U6c2VjcmV0", "Bearer "} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("Authorization %q = %d with WWW-Authenticate %q, want 401 with Bearer", authorization, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestAuthMiddlewareUnreachableIssuer(t *testing.T) {
+	iss := newIssuer(t)
+	token := iss.sign(t, "RS256", "k1", map[string]any{"iss": iss.URL, "exp": time.Now().Add(time.Hour).Unix()})
+
+	down := newIssuer(t)
+	down.down.Store(true)
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hung.Close()
+
+	for _, tc := range []struct {
+		name   string
+		issuer string
+		want   int
+	}{
+		{"failing", down.URL, http.StatusBadGateway},
+		{"timing out", hung.URL, http.StatusGatewayTimeout},
+	} {
+		h := AuthMiddleware(&Config{OidcIssuer: tc.issuer}, WithFetchTimeout(50*time.Millisecond))(http.NotFoundHandler())
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s issuer: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ora
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// BasicAuthMiddleware challenges requests without the given credentials, e.g.
+//
+//	http.Handle("/admin/", ora.BasicAuthMiddleware(user, pass)(ora.New(ora.WithBasename("/admin"))))
+//
+// It is a no-op when both username and password are empty.
+func BasicAuthMiddleware(username, password string) func(http.Handler) http.Handler {
+	if username == "" && password == "" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	// comparing digests keeps the comparison constant time regardless of the lengths
+	wantUser, wantPass := sha256.Sum256([]byte(username)), sha256.Sum256([]byte(password))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			gotUser, gotPass := sha256.Sum256([]byte(user)), sha256.Sum256([]byte(pass))
+			userMatch := subtle.ConstantTimeCompare(gotUser[:], wantUser[:])
+			passMatch := subtle.ConstantTimeCompare(gotPass[:], wantPass[:])
+			if !ok || userMatch&passMatch != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package ora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	h := BasicAuthMiddleware("admin", "s3cret")(next)
+
+	for _, tc := range []struct {
+		name       string
+		user, pass string
+		noAuth     bool
+		want       int
+	}{
+		{name: "correct", user: "admin", pass: "s3cret", want: http.StatusTeapot},
+		{name: "wrong user", user: "root", pass: "s3cret", want: http.StatusUnauthorized},
+		{name: "wrong password", user: "admin", pass: "guess", want: http.StatusUnauthorized},
+		{name: "empty password", user: "admin", want: http.StatusUnauthorized},
+		{name: "missing", noAuth: true, want: http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if !tc.noAuth {
+			r.SetBasicAuth(tc.user, tc.pass)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+		if challenge := w.Header().Get("WWW-Authenticate"); tc.want == http.StatusUnauthorized && !strings.HasPrefix(challenge, "Basic ") {
+			t.Errorf("%s: WWW-Authenticate = %q, want a Basic challenge", tc.name, challenge)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	BasicAuthMiddleware("", "")(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("empty credentials = %d, want next to be served", w.Code)
+	}
+}
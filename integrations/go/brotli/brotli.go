@@ -0,0 +1,46 @@
+// Package brotli serves the admin Brotli encoded to clients accepting it.
+//
+//	h := ora.New(ora.WithBasename("/admin"), ora.WithOpenapiDocUrl("/openapi.json"), brotli.Option())
+//
+// Bodies are compressed once at construction, br is preferred over gzip, which stays
+// the fallback, and clients accepting neither get the identity body. The shell, compressed
+// per response when security headers are on, gets a faster quality than the precompressed bodies.
+// It lives in its own package so that only users of it depend on a Brotli encoder.
+package brotli
+
+import (
+	"bytes"
+
+	cbrotli "github.com/andybalholm/brotli"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+// fastLevel is the quality the shell is compressed at per response, when security headers are on,
+// close to gzip's default in speed with a better ratio
+const fastLevel = 5
+
+// Option returns an ora.Option adding the br content coding, at the best compression
+func Option() ora.Option {
+	return OptionLevel(cbrotli.BestCompression)
+}
+
+// OptionLevel is Option at the given quality, from 0 to 11, lower is faster to compress.
+// The shell compressed per response with security headers on uses at most quality 5.
+func OptionLevel(level int) ora.Option {
+	return ora.WithEncoder("br", encode(level), encode(min(level, fastLevel)))
+}
+
+func encode(level int) func(body []byte) ([]byte, error) {
+	return func(body []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		bw := cbrotli.NewWriterLevel(&buf, level)
+		if _, err := bw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := bw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
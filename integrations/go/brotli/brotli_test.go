@@ -0,0 +1,67 @@
+package brotli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cbrotli "github.com/andybalholm/brotli"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+func decode(t *testing.T, coding string, body []byte) string {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(body)
+	switch coding {
+	case "br":
+		r = cbrotli.NewReader(r)
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decode %s: %v", coding, err)
+	}
+	return string(data)
+}
+
+func TestContentCodingSelection(t *testing.T) {
+	cfg := &ora.Config{OpenapiDocUrl: "/openapi.json"}
+	for name, h := range map[string]http.Handler{
+		"precompressed":    ora.NewWithConfig(cfg, Option()),
+		"security headers": ora.NewWithConfig(cfg, Option(), ora.WithSecurityHeaders()),
+	} {
+		for _, tc := range []struct{ accept, want string }{
+			{"gzip, br", "br"},
+			{"br;q=0.5, gzip;q=1", "br"},
+			{"gzip", "gzip"},
+			{"br;q=0, gzip", "gzip"},
+			{"br;q=0, gzip;q=0", ""},
+			{"identity", ""},
+			{"", ""},
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			got := w.Header().Get("Content-Encoding")
+			if got != tc.want {
+				t.Errorf("%s: Accept-Encoding %q served %q, want %q", name, tc.accept, got, tc.want)
+				continue
+			}
+			if body := decode(t, got, w.Body.Bytes()); !strings.Contains(body, "window.__ORA_CONFIG__") {
+				t.Errorf("%s: Accept-Encoding %q: decoded body isn't the shell: %q", name, tc.accept, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/saltbo/openapi-rest-admin/integrations/go/brotli
+
+go 1.24.0
+
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/saltbo/openapi-rest-admin/integrations/go v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+)
+
+// the core module is developed alongside, releases require its tagged version
+replace github.com/saltbo/openapi-rest-admin/integrations/go => ../
@@ -1,5 +1,24 @@
 package ora
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+var errNilConfig = errors.New("ora: config is nil")
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
 type Config struct {
 	Basename         string `json:"basename"`
 	OpenapiDocUrl    string `json:"openapiDocUrl"`
@@ -10,4 +29,260 @@ type Config struct {
 	OidcResponseType string `json:"oidcResponseType"`
 	OidcScope        string `json:"oidcScope"`
 	OidcAudience     string `json:"oidcAudience"`
+
+	// PublicPath is where the browser sees the admin, when a path rewriting proxy
+	// mounts the handler's Basename elsewhere, e.g. /tools/admin for a Basename of /.
+	// It defaults to Basename, the frontend routes and the <base href> of the index use it,
+	// custom templates without a <base> of their own get one.
+	PublicPath string `json:"publicPath,omitempty"`
+
+	// OidcUsePKCE defaults to true for the code response type and false for the others
+	OidcUsePKCE               *bool  `json:"oidcUsePKCE,omitempty"`
+	OidcSilentRenew           bool   `json:"oidcSilentRenew,omitempty"`
+	OidcPostLogoutRedirectUri string `json:"oidcPostLogoutRedirectUri,omitempty"`
+
+	// OidcEndSessionEndpoint is where logout sends users to end the IdP session,
+	// WithOIDCDiscovery fills it from the issuer's discovery document
+	OidcEndSessionEndpoint string `json:"oidcEndSessionEndpoint,omitempty"`
+
+	// branding, empty values keep the built-in defaults,
+	// without FaviconUrl the initials of AppTitle on PrimaryColor are served at Basename/favicon.svg
+	LogoUrl      string `json:"logoUrl,omitempty"`
+	FaviconUrl   string `json:"faviconUrl,omitempty"`
+	PrimaryColor string `json:"primaryColor,omitempty"` // CSS hex color, e.g. #1677ff
+
+	// DefaultLocale is the BCP-47 tag the frontend starts in, e.g. zh-CN,
+	// AvailableLocales restricts the language switcher when set
+	DefaultLocale    string   `json:"defaultLocale,omitempty"`
+	AvailableLocales []string `json:"availableLocales,omitempty"`
+
+	// OpenapiDocProxyPath, when set, makes the handler fetch OpenapiDocUrl server-side
+	// and serve it at Basename + OpenapiDocProxyPath, the frontend is pointed there instead
+	OpenapiDocProxyPath string `json:"-"`
+
+	// OpenapiDocFile is a local JSON or YAML spec served under the basename instead of OpenapiDocUrl
+	OpenapiDocFile string `json:"-"`
+
+	// OpenapiDocs lists the documents the frontend lets users switch between,
+	// OpenapiDocUrl alone is still treated as the sole document
+	OpenapiDocs []DocRef `json:"openapiDocs,omitempty"`
+
+	// ApiProxyTarget, when set, is the base url of the backend API proxied at Basename + ApiProxyPath
+	ApiProxyTarget string `json:"-"`
+	ApiProxyPath   string `json:"-"`
+
+	// ReadOnly hides the create, update and delete actions of the frontend.
+	// Only the API proxy enforces it, by rejecting other methods than GET, HEAD and OPTIONS,
+	// without the proxy the backend API stays writable for anyone calling it directly.
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// resource tables, zero values keep the built-in defaults
+	DefaultPageSize int    `json:"defaultPageSize,omitempty"`
+	TableDensity    string `json:"tableDensity,omitempty"` // comfortable or compact
+
+	// Build is stamped into the configuration, and served at Basename/__ora/version with WithVersionEndpoint.
+	// It defaults to the version of this module in the configuration, the endpoint adds
+	// the Go version and VCS stamps of the binary.
+	Build *BuildInfo `json:"build,omitempty"`
+
+	// HeadHTML is inserted as is at the end of the index's <head>, for analytics snippets,
+	// meta or preconnect tags. It is not escaped, so it must come from a trusted source,
+	// never from user input. With security headers on, inline scripts need nonce="{nonce}" to run,
+	// {nonce} is replaced by the CSP nonce, and the default policy allows the origins of
+	// its src and href attributes for scripts, styles and fonts.
+	HeadHTML string `json:"-"`
+
+	// Extra is merged into the configuration handed to the frontend, for settings this package
+	// doesn't know about such as feature flags, its keys must not collide with the other fields
+	Extra map[string]any `json:"-"`
+}
+
+// DocRef is an OpenAPI document offered by the document switcher
+type DocRef struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+// plain is Config without its methods, so MarshalJSON doesn't recurse
+type plain Config
+
+// withoutOIDC shadows the OIDC fields of plain, which are then left out,
+// so the frontend of a config without an issuer doesn't start an OIDC client
+type withoutOIDC struct {
+	plain
+	OidcIssuer                *struct{} `json:"oidcIssuer,omitempty"`
+	OidcClientId              *struct{} `json:"oidcClientId,omitempty"`
+	OidcRedirectUri           *struct{} `json:"oidcRedirectUri,omitempty"`
+	OidcResponseType          *struct{} `json:"oidcResponseType,omitempty"`
+	OidcScope                 *struct{} `json:"oidcScope,omitempty"`
+	OidcAudience              *struct{} `json:"oidcAudience,omitempty"`
+	OidcUsePKCE               *struct{} `json:"oidcUsePKCE,omitempty"`
+	OidcSilentRenew           *struct{} `json:"oidcSilentRenew,omitempty"`
+	OidcPostLogoutRedirectUri *struct{} `json:"oidcPostLogoutRedirectUri,omitempty"`
+	OidcEndSessionEndpoint    *struct{} `json:"oidcEndSessionEndpoint,omitempty"`
+}
+
+// configKeys are the JSON names of the Config fields, Extra must not reuse them even when they are left out
+var configKeys = sync.OnceValue(func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+})
+
+// MarshalJSON encodes the configuration handed to the frontend, with Extra merged in.
+// Without OidcIssuer the OIDC fields are left out, with it they are all kept, even when empty.
+func (c Config) MarshalJSON() ([]byte, error) {
+	var v any = plain(c)
+	if c.OidcIssuer == "" {
+		v = withoutOIDC{plain: plain(c)}
+	}
+	base, err := json.Marshal(v)
+	if err != nil || len(c.Extra) == 0 {
+		return base, err
+	}
+
+	keys := make([]string, 0, len(c.Extra))
+	for key := range c.Extra {
+		if configKeys()[key] {
+			return nil, fmt.Errorf("ora: invalid config: Extra key %q collides with a Config field", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// append the extras sorted, after the known fields, so the output is stable
+	buf := bytes.NewBuffer(base[:len(base)-1])
+	for _, key := range keys {
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(c.Extra[key])
+		if err != nil {
+			return nil, fmt.Errorf("ora: invalid config: Extra key %q: %w", key, err)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Basename:         "/",
+		OidcResponseType: "code",
+		OidcScope:        "openid profile email",
+	}
+}
+
+func (c *Config) validate() error {
+	if c.OidcIssuer != "" && c.OidcClientId == "" {
+		return fmt.Errorf("ora: invalid config: OidcClientId is required when OidcIssuer is set")
+	}
+	if c.OidcUsePKCE != nil && *c.OidcUsePKCE && !c.isCodeFlow() {
+		return fmt.Errorf("ora: invalid config: OidcUsePKCE requires the code OidcResponseType, got %q", c.OidcResponseType)
+	}
+	if c.OidcPostLogoutRedirectUri != "" && !isAbsoluteURL(c.OidcPostLogoutRedirectUri) {
+		return fmt.Errorf("ora: invalid config: OidcPostLogoutRedirectUri %q is not an absolute url", c.OidcPostLogoutRedirectUri)
+	}
+	if c.OidcEndSessionEndpoint != "" && !isAbsoluteURL(c.OidcEndSessionEndpoint) {
+		return fmt.Errorf("ora: invalid config: OidcEndSessionEndpoint %q is not an absolute url", c.OidcEndSessionEndpoint)
+	}
+	if c.PrimaryColor != "" && !hexColor.MatchString(c.PrimaryColor) {
+		return fmt.Errorf("ora: invalid config: PrimaryColor %q is not a CSS hex color", c.PrimaryColor)
+	}
+	if c.DefaultPageSize < 0 {
+		return fmt.Errorf("ora: invalid config: DefaultPageSize must be positive, got %d", c.DefaultPageSize)
+	}
+	if c.TableDensity != "" && c.TableDensity != "comfortable" && c.TableDensity != "compact" {
+		return fmt.Errorf("ora: invalid config: TableDensity must be comfortable or compact, got %q", c.TableDensity)
+	}
+	if err := c.validateLocales(); err != nil {
+		return err
+	}
+	if err := c.validateDocs(); err != nil {
+		return err
+	}
+	if err := c.validateAPIProxy(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// isCodeFlow reports whether the authorization code flow is used, which is the frontend's default
+func (c *Config) isCodeFlow() bool {
+	return c.OidcResponseType == "" || c.OidcResponseType == "code"
+}
+
+func (c *Config) validateLocales() error {
+	if c.DefaultLocale != "" {
+		if _, err := language.Parse(c.DefaultLocale); err != nil {
+			return fmt.Errorf("ora: invalid config: DefaultLocale %q is not a BCP-47 tag", c.DefaultLocale)
+		}
+	}
+
+	found := false
+	for _, locale := range c.AvailableLocales {
+		if _, err := language.Parse(locale); err != nil {
+			return fmt.Errorf("ora: invalid config: AvailableLocales %q is not a BCP-47 tag", locale)
+		}
+		found = found || strings.EqualFold(locale, c.DefaultLocale)
+	}
+	if c.DefaultLocale != "" && len(c.AvailableLocales) > 0 && !found {
+		return fmt.Errorf("ora: invalid config: DefaultLocale %q is not in AvailableLocales", c.DefaultLocale)
+	}
+	return nil
+}
+
+func (c *Config) validateDocs() error {
+	names := make(map[string]bool, len(c.OpenapiDocs))
+	for i, doc := range c.OpenapiDocs {
+		if doc.Name == "" {
+			return fmt.Errorf("ora: invalid config: OpenapiDocs[%d].Name is required", i)
+		}
+		if doc.Url == "" {
+			return fmt.Errorf("ora: invalid config: OpenapiDocs[%d].Url is required", i)
+		}
+		if names[doc.Name] {
+			return fmt.Errorf("ora: invalid config: OpenapiDocs[%d].Name %q is duplicated", i, doc.Name)
+		}
+		names[doc.Name] = true
+	}
+	return nil
+}
+
+func (c *Config) validateAPIProxy() error {
+	if c.ApiProxyTarget == "" && c.ApiProxyPath == "" {
+		return nil
+	}
+	if c.ApiProxyTarget == "" {
+		return fmt.Errorf("ora: invalid config: ApiProxyTarget is required when ApiProxyPath is set")
+	}
+	if c.ApiProxyPath == "" {
+		return fmt.Errorf("ora: invalid config: ApiProxyPath is required when ApiProxyTarget is set")
+	}
+	if !isAbsoluteURL(c.ApiProxyTarget) {
+		return fmt.Errorf("ora: invalid config: ApiProxyTarget %q is not an absolute url", c.ApiProxyTarget)
+	}
+	return nil
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// NormalizeBasename returns basename with exactly one leading slash and no trailing slash,
+// the root is always "/". It is what handlers strip from request paths, adapters mounting them
+// on a router register their routes on it.
+func NormalizeBasename(basename string) string {
+	basename = strings.Trim(strings.TrimSpace(basename), "/")
+	return "/" + basename
 }
@@ -0,0 +1,115 @@
+package ora
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeBasename(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		outside  string // a path the handler must not serve
+	}{
+		{"", "/", ""},
+		{"/", "/", ""},
+		{"admin", "/admin", "/administrator/"},
+		{"/admin/", "/admin", "/other/"},
+		{"admin/sub", "/admin/sub", "/admin/"},
+	} {
+		if got := NormalizeBasename(tc.in); got != tc.want {
+			t.Errorf("NormalizeBasename(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+
+		h := NewWithConfig(&Config{Basename: tc.in, OpenapiDocUrl: "/openapi.json"})
+		root := tc.want
+		if root != "/" {
+			root += "/"
+		}
+		for _, target := range []string{root, root + "resources/users"} {
+			w := get(h, target)
+			if w.Code != http.StatusOK {
+				t.Errorf("Basename %q: GET %s = %d, want 200", tc.in, target, w.Code)
+				continue
+			}
+			if got := configOf(t, w.Body.String())["basename"]; got != tc.want {
+				t.Errorf("Basename %q: emitted basename = %v, want %q", tc.in, got, tc.want)
+			}
+		}
+		if tc.outside != "" {
+			if w := get(h, tc.outside); w.Code != http.StatusNotFound {
+				t.Errorf("Basename %q: GET %s = %d, want 404", tc.in, tc.outside, w.Code)
+			}
+		}
+	}
+}
+
+func TestOIDCClientSettingsInConfig(t *testing.T) {
+	oidc := func(responseType string) *Config {
+		return &Config{
+			OpenapiDocUrl:             "/openapi.json",
+			OidcIssuer:                "https://idp.example.com",
+			OidcClientId:              "admin",
+			OidcResponseType:          responseType,
+			OidcSilentRenew:           true,
+			OidcPostLogoutRedirectUri: "https://admin.example.com/",
+		}
+	}
+
+	for _, tc := range []struct {
+		responseType string
+		wantPKCE     bool
+	}{
+		{"", true},
+		{"code", true},
+		{"id_token token", false},
+	} {
+		cfg := configOf(t, get(NewWithConfig(oidc(tc.responseType)), "/").Body.String())
+		if cfg["oidcUsePKCE"] != tc.wantPKCE {
+			t.Errorf("response type %q: oidcUsePKCE = %v, want %v", tc.responseType, cfg["oidcUsePKCE"], tc.wantPKCE)
+		}
+		if cfg["oidcSilentRenew"] != true || cfg["oidcPostLogoutRedirectUri"] != "https://admin.example.com/" {
+			t.Errorf("response type %q: silent renew and logout redirect not passed through in %v", tc.responseType, cfg)
+		}
+	}
+
+	pkce := false
+	cfg := oidc("code")
+	cfg.OidcUsePKCE = &pkce
+	if got := configOf(t, get(NewWithConfig(cfg), "/").Body.String())["oidcUsePKCE"]; got != false {
+		t.Errorf("explicit OidcUsePKCE false emitted as %v", got)
+	}
+
+	pkce = true
+	cfg = oidc("id_token")
+	cfg.OidcUsePKCE = &pkce
+	if _, err := NewHandler(cfg); err == nil {
+		t.Error("NewHandler accepted PKCE without the code flow")
+	}
+}
+
+func TestMarshalJSONOmitsUnconfiguredOIDC(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "without OIDC",
+			cfg:  Config{OpenapiDocUrl: "/openapi.json", OidcScope: "openid", OidcSilentRenew: true},
+			want: `{"basename":"","openapiDocUrl":"/openapi.json","appTitle":""}`,
+		},
+		{
+			name: "with OIDC",
+			cfg:  Config{OpenapiDocUrl: "/openapi.json", OidcIssuer: "https://idp.example.com", OidcClientId: "admin"},
+			want: `{"basename":"","openapiDocUrl":"/openapi.json","appTitle":"","oidcIssuer":"https://idp.example.com","oidcClientId":"admin","oidcRedirectUri":"","oidcResponseType":"","oidcScope":"","oidcAudience":""}`,
+		},
+	} {
+		got, err := tc.cfg.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: MarshalJSON() = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
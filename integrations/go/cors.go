@@ -0,0 +1,63 @@
+package ora
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	corsAllowMethods  = "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type, X-Request-ID"
+	corsExposeHeaders = "ETag, Location, X-Request-ID"
+	corsMaxAge        = "600"
+)
+
+// CORSMiddleware lets the admin, served from one of allowedOrigins, call the wrapped API mux
+//
+// The request origin is echoed back only when it is in allowedOrigins, "*" allows any origin
+// and is meant for development. Preflight requests are answered with 204 without reaching next.
+func CORSMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			switch {
+			case allowed[origin]:
+				h.Set("Access-Control-Allow-Origin", origin)
+			case allowed["*"]:
+				h.Set("Access-Control-Allow-Origin", "*")
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			headers := r.Header.Get("Access-Control-Request-Headers")
+			if headers == "" {
+				headers = corsAllowHeaders
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", corsAllowMethods)
+			h.Set("Access-Control-Allow-Headers", headers)
+			h.Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package ora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	var reached bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true })
+
+	for _, tc := range []struct {
+		name     string
+		allowed  []string
+		method   string
+		origin   string
+		wantACAO string
+		wantNext bool
+	}{
+		{"listed origin", []string{"https://admin.example.com/"}, http.MethodGet, "https://admin.example.com", "https://admin.example.com", true},
+		{"unlisted origin", []string{"https://admin.example.com"}, http.MethodGet, "https://evil.example.com", "", true},
+		{"any origin", []string{"*"}, http.MethodPost, "https://dev.localhost", "*", true},
+		{"preflight", []string{"https://admin.example.com"}, http.MethodOptions, "https://admin.example.com", "https://admin.example.com", false},
+		{"unlisted preflight", []string{"https://admin.example.com"}, http.MethodOptions, "https://evil.example.com", "", true},
+	} {
+		reached = false
+		r := httptest.NewRequest(tc.method, "/users", nil)
+		r.Header.Set("Origin", tc.origin)
+		if tc.method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+		}
+		w := httptest.NewRecorder()
+		CORSMiddleware(tc.allowed)(next).ServeHTTP(w, r)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.wantACAO {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", tc.name, got, tc.wantACAO)
+		}
+		if !slices.Contains(w.Header().Values("Vary"), "Origin") {
+			t.Errorf("%s: Vary = %q, want Origin", tc.name, w.Header().Values("Vary"))
+		}
+		if reached != tc.wantNext {
+			t.Errorf("%s: reached next = %v, want %v", tc.name, reached, tc.wantNext)
+		}
+		if tc.name == "preflight" && (w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") == "") {
+			t.Errorf("preflight = %d with methods %q, want 204 with the allowed methods", w.Code, w.Header().Get("Access-Control-Allow-Methods"))
+		}
+	}
+
+	reached = false
+	w := httptest.NewRecorder()
+	CORSMiddleware([]string{"*"})(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if !reached || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("same origin request got ACAO %q, reached next = %v", w.Header().Get("Access-Control-Allow-Origin"), reached)
+	}
+}
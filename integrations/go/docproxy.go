@@ -0,0 +1,176 @@
+package ora
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// docProxyTTL is how long a fetched document is served before it is revalidated upstream
+	docProxyTTL = time.Minute
+	// docProxyBackoff is how long a failed fetch is not retried, so a down upstream isn't hammered
+	docProxyBackoff = 10 * time.Second
+)
+
+// docProxy serves the OpenAPI document fetched from the upstream,
+// for specs living on services that don't send CORS headers
+type docProxy struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+	filter  func([]byte) ([]byte, error)
+	logger  *slog.Logger
+
+	group singleflight.Group
+
+	mu        sync.Mutex
+	doc       *cachedDoc
+	failure   error
+	failureAt time.Time
+}
+
+type cachedDoc struct {
+	body         []byte
+	contentType  string
+	etag         string
+	upstreamETag string
+	fetchedAt    time.Time
+}
+
+func (p *docProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	doc, err := p.load(r)
+	if err != nil {
+		upstreamError(w, err, "failed to fetch the OpenAPI document")
+		return
+	}
+
+	w.Header().Set("ETag", doc.etag)
+	if etagMatch(r, doc.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.contentType)
+	writeBody(w, r, doc.body)
+}
+
+// load returns the cached document, revalidating it upstream once it is stale.
+// A stale copy is still served if the upstream is unavailable.
+//
+// Concurrent loads share a single fetch, detached from the requests waiting on it so one
+// client going away doesn't fail the others, and bounded by the fetch timeout instead.
+// After a failure the upstream is left alone for docProxyBackoff.
+func (p *docProxy) load(r *http.Request) (*cachedDoc, error) {
+	p.mu.Lock()
+	doc, failure, failureAt := p.doc, p.failure, p.failureAt
+	p.mu.Unlock()
+
+	if doc != nil && time.Since(doc.fetchedAt) < docProxyTTL {
+		return doc, nil
+	}
+	if failure != nil && time.Since(failureAt) < docProxyBackoff {
+		if doc != nil {
+			return doc, nil
+		}
+		return nil, failure
+	}
+
+	ch := p.group.DoChan("doc", func() (any, error) {
+		return p.refresh(context.WithoutCancel(r.Context()))
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*cachedDoc), nil
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+}
+
+// refresh fetches the document and records the outcome, it returns the stale copy when the fetch fails
+func (p *docProxy) refresh(ctx context.Context) (*cachedDoc, error) {
+	p.mu.Lock()
+	prev := p.doc
+	p.mu.Unlock()
+
+	doc, err := p.fetch(ctx, prev)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.logger.WarnContext(ctx, "ora: fetch OpenAPI document failed",
+			slog.String("url", p.url),
+			slog.Bool("stale", prev != nil),
+			slog.Any("error", err),
+		)
+		p.failure, p.failureAt = err, time.Now()
+		if prev != nil {
+			return prev, nil
+		}
+		return nil, err
+	}
+
+	p.doc, p.failure = doc, nil
+	return doc, nil
+}
+
+func (p *docProxy) fetch(ctx context.Context, prev *cachedDoc) (*cachedDoc, error) {
+	ctx, cancel := fetchContext(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.upstreamETag != "" {
+		req.Header.Set("If-None-Match", prev.upstreamETag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && prev != nil {
+		doc := *prev
+		doc.fetchedAt = time.Now()
+		return &doc, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ora: fetch %s: unexpected status %s", p.url, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// the filtered document is what gets cached, revalidations answered 304 reuse it
+	if p.filter != nil {
+		if body, err = p.filter(body); err != nil {
+			return nil, fmt.Errorf("ora: filter %s: %w", p.url, err)
+		}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	return &cachedDoc{
+		body:         body,
+		contentType:  contentType,
+		etag:         etagOf(body),
+		upstreamETag: resp.Header.Get("ETag"),
+		fetchedAt:    time.Now(),
+	}, nil
+}
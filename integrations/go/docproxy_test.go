@@ -0,0 +1,108 @@
+package ora
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDocProxySlowUpstream(t *testing.T) {
+	var hits atomic.Int64
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	h, err := NewHandler(&Config{OpenapiDocUrl: upstream.URL, OpenapiDocProxyPath: "/openapi.json"}, WithFetchTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+			if w.Code != http.StatusGatewayTimeout {
+				t.Errorf("status = %d, want 504", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("concurrent requests took %s, want them to share one 300ms fetch", elapsed)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("upstream hit %d times, want 1", n)
+	}
+
+	// the failure is remembered rather than retried right away
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusGatewayTimeout || hits.Load() != 1 {
+		t.Errorf("after the failure: status = %d, hits = %d, want 504 without a new fetch", w.Code, hits.Load())
+	}
+}
+
+func TestDocProxyServesStaleOnFailure(t *testing.T) {
+	var fail atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"openapi":"3.0.0"}`))
+	}))
+	defer upstream.Close()
+
+	p := &docProxy{url: upstream.URL, client: defaultClient, timeout: time.Second, logger: newOptions(defaultConfig(), nil).logger}
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		return w
+	}
+	if w := serve(); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	fail.Store(true)
+	p.doc.fetchedAt = time.Now().Add(-docProxyTTL)
+	if w := serve(); w.Code != http.StatusOK || w.Body.String() != `{"openapi":"3.0.0"}` {
+		t.Errorf("stale: status = %d, body = %q, want the cached document", w.Code, w.Body)
+	}
+}
+
+func TestDocProxyClientCancel(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+
+	p := &docProxy{url: upstream.URL, client: defaultClient, timeout: time.Second, logger: newOptions(defaultConfig(), nil).logger}
+	r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	ctx, cancel := context.WithCancel(r.Context())
+	cancel()
+	p.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+
+	// the shared fetch outlives the client that started it
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package ora
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// dynamicCacheSize is how many configs NewDynamic keeps built handlers for
+const dynamicCacheSize = 64
+
+// NewDynamic return a http.Handler resolving the config of every request, e.g. from its Host or a path segment,
+// so tenants can be added without redeploying. opts apply on top of every resolved config.
+//
+// The handlers built for the most recently used configs are cached by a fingerprint of the config,
+// a resolved config that didn't change is served without being rendered again.
+// resolve returns a nil config for requests it has none for, they are answered 404,
+// errors from resolve and invalid configs are logged and answered 500.
+func NewDynamic(resolve func(*http.Request) (*Config, error), opts ...Option) http.Handler {
+	return &dynamic{
+		resolve: resolve,
+		opts:    opts,
+		logger:  newOptions(defaultConfig(), opts).logger,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+type dynamic struct {
+	resolve func(*http.Request) (*Config, error)
+	opts    []Option
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	lru     *list.List // of *dynamicEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type dynamicEntry struct {
+	key     string
+	handler http.Handler
+}
+
+func (d *dynamic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg, err := d.resolve(r)
+	if err != nil {
+		d.logger.ErrorContext(r.Context(), "ora: resolve config failed",
+			slog.String("host", r.Host),
+			slog.String("path", r.URL.Path),
+			slog.Any("error", err),
+		)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if cfg == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h, err := d.handler(cfg)
+	if err != nil {
+		d.logger.ErrorContext(r.Context(), "ora: build handler failed",
+			slog.String("host", r.Host),
+			slog.String("path", r.URL.Path),
+			slog.Any("error", err),
+		)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// handler returns the cached handler of cfg, building it on a miss
+func (d *dynamic) handler(cfg *Config) (http.Handler, error) {
+	key, err := fingerprint(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if e, ok := d.entries[key]; ok {
+		d.lru.MoveToFront(e)
+		d.mu.Unlock()
+		return e.Value.(*dynamicEntry).handler, nil
+	}
+	d.mu.Unlock()
+
+	// building may reach the network for OIDC discovery, so it runs unlocked,
+	// concurrent misses of the same config build it twice and the first one is kept
+	h, err := build(cfg, d.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e, ok := d.entries[key]; ok {
+		d.lru.MoveToFront(e)
+		return e.Value.(*dynamicEntry).handler, nil
+	}
+	d.entries[key] = d.lru.PushFront(&dynamicEntry{key: key, handler: h})
+	if d.lru.Len() > dynamicCacheSize {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.entries, oldest.Value.(*dynamicEntry).key)
+	}
+	return h, nil
+}
+
+// fingerprint identifies cfg by what the frontend sees and the server-side only fields
+func fingerprint(cfg *Config) (string, error) {
+	configuration, err := cfg.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal([]any{
+		json.RawMessage(configuration),
+		cfg.OpenapiDocProxyPath, cfg.OpenapiDocFile,
+		cfg.ApiProxyTarget, cfg.ApiProxyPath,
+		cfg.HeadHTML,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,105 @@
+package ora
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+// countingFS counts how often the index is read, once per handler built
+type countingFS struct {
+	fstest.MapFS
+	reads *atomic.Int64
+}
+
+func (c countingFS) ReadFile(name string) ([]byte, error) {
+	if name == indexName {
+		c.reads.Add(1)
+	}
+	return c.MapFS.ReadFile(name)
+}
+
+func tenantAssets(builds *atomic.Int64) fs.FS {
+	return countingFS{MapFS: fstest.MapFS{
+		indexName: {Data: []byte(`<html><head></head><body><script>window.__ORA_CONFIG__ = '<<configuration>>';</script></body></html>`)},
+	}, reads: builds}
+}
+
+func getHost(h http.Handler, host, target string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Host = host
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestDynamic(t *testing.T) {
+	var builds atomic.Int64
+	h := NewDynamic(func(r *http.Request) (*Config, error) {
+		switch r.Host {
+		case "broken.example.com":
+			return nil, errors.New("tenant store down")
+		case "invalid.example.com":
+			return &Config{}, nil // no OpenAPI document
+		case "unknown.example.com":
+			return nil, nil
+		}
+		return &Config{OpenapiDocUrl: "https://" + r.Host + "/openapi.json", AppTitle: r.Host}, nil
+	}, WithFS(tenantAssets(&builds)))
+
+	for host, want := range map[string]int{
+		"broken.example.com":  http.StatusInternalServerError,
+		"invalid.example.com": http.StatusInternalServerError,
+		"unknown.example.com": http.StatusNotFound,
+	} {
+		if w := getHost(h, host, "/"); w.Code != want {
+			t.Errorf("GET %s = %d, want %d", host, w.Code, want)
+		}
+	}
+
+	builds.Store(0)
+	for range 3 {
+		for _, host := range []string{"a.example.com", "b.example.com"} {
+			w := getHost(h, host, "/users")
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s = %d, want 200", host, w.Code)
+			}
+			if cfg := configOf(t, w.Body.String()); cfg["appTitle"] != host || cfg["openapiDocUrl"] != "https://"+host+"/openapi.json" {
+				t.Errorf("GET %s served %v, want its own config", host, cfg)
+			}
+		}
+	}
+	if n := builds.Load(); n != 2 {
+		t.Errorf("built %d handlers for 2 configs", n)
+	}
+}
+
+func TestDynamicEviction(t *testing.T) {
+	var builds atomic.Int64
+	h := NewDynamic(func(r *http.Request) (*Config, error) {
+		return &Config{OpenapiDocUrl: "/openapi.json", AppTitle: r.Host}, nil
+	}, WithFS(tenantAssets(&builds)))
+	host := func(i int) string { return "t" + strconv.Itoa(i) + ".example.com" }
+
+	for i := range dynamicCacheSize + 1 {
+		getHost(h, host(i), "/")
+	}
+	if n := h.(*dynamic).lru.Len(); n != dynamicCacheSize {
+		t.Errorf("%d handlers cached, want %d", n, dynamicCacheSize)
+	}
+
+	builds.Store(0)
+	getHost(h, host(dynamicCacheSize), "/") // the most recent one is still cached
+	if n := builds.Load(); n != 0 {
+		t.Errorf("recently used config rebuilt %d times", n)
+	}
+	getHost(h, host(0), "/") // the least recent one was evicted
+	if n := builds.Load(); n != 1 {
+		t.Errorf("evicted config built %d times, want once", n)
+	}
+}
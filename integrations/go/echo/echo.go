@@ -0,0 +1,35 @@
+// Package oraecho registers the admin on an echo server.
+//
+//	cfg := &ora.Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json"}
+//	oraecho.Register(e, cfg)
+package oraecho
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+// Register registers the admin on cfg.Basename of e, it panics if the config is invalid
+func Register(e *echo.Echo, cfg *ora.Config, opts ...ora.Option) {
+	register(e.Any, strings.TrimSuffix(ora.NormalizeBasename(cfg.Basename), "/"), cfg, opts)
+}
+
+// RegisterGroup registers the admin on g, which must be the group of cfg.Basename
+//
+//	oraecho.RegisterGroup(e.Group("/admin", middleware.BasicAuth(validator)), cfg)
+func RegisterGroup(g *echo.Group, cfg *ora.Config, opts ...ora.Option) {
+	register(g.Any, "", cfg, opts)
+}
+
+type routeFunc func(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) []*echo.Route
+
+func register(route routeFunc, prefix string, cfg *ora.Config, opts []ora.Option) {
+	// the wrapped handler sees the full request path and strips the basename itself,
+	// every method is routed so the API proxy gets the mutations
+	h := echo.WrapHandler(ora.NewWithConfig(cfg, opts...))
+	route(prefix, h)
+	route(prefix+"/*", h)
+}
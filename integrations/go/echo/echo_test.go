@@ -0,0 +1,33 @@
+package oraecho
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+	"github.com/saltbo/openapi-rest-admin/integrations/go/internal/adaptertest"
+)
+
+// registrations are the ways of registering the admin on an echo server
+var registrations = map[string]adaptertest.Mount{
+	"echo": func(cfg *ora.Config, opts ...ora.Option) http.Handler {
+		e := echo.New()
+		Register(e, cfg, opts...)
+		return e
+	},
+	"group": func(cfg *ora.Config, opts ...ora.Option) http.Handler {
+		e := echo.New()
+		RegisterGroup(e.Group("/admin"), cfg, opts...)
+		return e
+	},
+}
+
+func TestRegisterProxiesMutations(t *testing.T) {
+	adaptertest.ProxiesMutations(t, registrations)
+}
+
+func TestRegisterServesTheShell(t *testing.T) {
+	adaptertest.ServesShell(t, registrations)
+}
@@ -0,0 +1,137 @@
+package ora
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NewFromEnv return a *Handler configured by ConfigFromEnv
+func NewFromEnv(opts ...Option) (*Handler, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewHandler(cfg, opts...)
+}
+
+// ConfigFromEnv builds a Config from the environment, unset variables keep the defaults of New
+//
+//	ORA_BASENAME                       Basename
+//	ORA_PUBLIC_PATH                    PublicPath
+//	ORA_OPENAPI_DOC_URL                OpenapiDocUrl
+//	ORA_OPENAPI_DOC_PROXY_PATH         OpenapiDocProxyPath
+//	ORA_OPENAPI_DOC_FILE               OpenapiDocFile
+//	ORA_OPENAPI_DOCS                   OpenapiDocs, as comma separated name=url pairs
+//	ORA_API_PROXY_TARGET               ApiProxyTarget
+//	ORA_API_PROXY_PATH                 ApiProxyPath
+//	ORA_READ_ONLY                      ReadOnly, as a boolean
+//	ORA_APP_TITLE                      AppTitle
+//	ORA_OIDC_ISSUER                    OidcIssuer
+//	ORA_OIDC_CLIENT_ID                 OidcClientId
+//	ORA_OIDC_REDIRECT_URI              OidcRedirectUri
+//	ORA_OIDC_RESPONSE_TYPE             OidcResponseType
+//	ORA_OIDC_SCOPE                     OidcScope
+//	ORA_OIDC_AUDIENCE                  OidcAudience
+//	ORA_OIDC_USE_PKCE                  OidcUsePKCE, as a boolean
+//	ORA_OIDC_SILENT_RENEW              OidcSilentRenew, as a boolean
+//	ORA_OIDC_POST_LOGOUT_REDIRECT_URI  OidcPostLogoutRedirectUri
+//	ORA_OIDC_END_SESSION_ENDPOINT      OidcEndSessionEndpoint
+//	ORA_LOGO_URL                       LogoUrl
+//	ORA_FAVICON_URL                    FaviconUrl
+//	ORA_PRIMARY_COLOR                  PrimaryColor
+//	ORA_HEAD_HTML                      HeadHTML
+//	ORA_DEFAULT_PAGE_SIZE              DefaultPageSize, as an integer
+//	ORA_TABLE_DENSITY                  TableDensity
+//	ORA_DEFAULT_LOCALE                 DefaultLocale
+//	ORA_AVAILABLE_LOCALES              AvailableLocales, comma separated
+//
+// One of ORA_OPENAPI_DOC_URL, ORA_OPENAPI_DOCS or ORA_OPENAPI_DOC_FILE is required.
+func ConfigFromEnv() (*Config, error) {
+	cfg := defaultConfig()
+	for name, field := range map[string]*string{
+		"ORA_BASENAME":                      &cfg.Basename,
+		"ORA_PUBLIC_PATH":                   &cfg.PublicPath,
+		"ORA_OPENAPI_DOC_URL":               &cfg.OpenapiDocUrl,
+		"ORA_OPENAPI_DOC_PROXY_PATH":        &cfg.OpenapiDocProxyPath,
+		"ORA_OPENAPI_DOC_FILE":              &cfg.OpenapiDocFile,
+		"ORA_API_PROXY_TARGET":              &cfg.ApiProxyTarget,
+		"ORA_API_PROXY_PATH":                &cfg.ApiProxyPath,
+		"ORA_APP_TITLE":                     &cfg.AppTitle,
+		"ORA_OIDC_ISSUER":                   &cfg.OidcIssuer,
+		"ORA_OIDC_CLIENT_ID":                &cfg.OidcClientId,
+		"ORA_OIDC_REDIRECT_URI":             &cfg.OidcRedirectUri,
+		"ORA_OIDC_RESPONSE_TYPE":            &cfg.OidcResponseType,
+		"ORA_OIDC_SCOPE":                    &cfg.OidcScope,
+		"ORA_OIDC_AUDIENCE":                 &cfg.OidcAudience,
+		"ORA_OIDC_POST_LOGOUT_REDIRECT_URI": &cfg.OidcPostLogoutRedirectUri,
+		"ORA_OIDC_END_SESSION_ENDPOINT":     &cfg.OidcEndSessionEndpoint,
+		"ORA_LOGO_URL":                      &cfg.LogoUrl,
+		"ORA_FAVICON_URL":                   &cfg.FaviconUrl,
+		"ORA_PRIMARY_COLOR":                 &cfg.PrimaryColor,
+		"ORA_HEAD_HTML":                     &cfg.HeadHTML,
+		"ORA_TABLE_DENSITY":                 &cfg.TableDensity,
+		"ORA_DEFAULT_LOCALE":                &cfg.DefaultLocale,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			*field = v
+		}
+	}
+
+	if v := os.Getenv("ORA_OIDC_USE_PKCE"); v != "" {
+		usePKCE, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("ora: invalid env: ORA_OIDC_USE_PKCE: %w", err)
+		}
+		cfg.OidcUsePKCE = &usePKCE
+	}
+	if v := os.Getenv("ORA_OIDC_SILENT_RENEW"); v != "" {
+		silentRenew, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("ora: invalid env: ORA_OIDC_SILENT_RENEW: %w", err)
+		}
+		cfg.OidcSilentRenew = silentRenew
+	}
+	if v := os.Getenv("ORA_READ_ONLY"); v != "" {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("ora: invalid env: ORA_READ_ONLY: %w", err)
+		}
+		cfg.ReadOnly = readOnly
+	}
+	if v := os.Getenv("ORA_DEFAULT_PAGE_SIZE"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("ora: invalid env: ORA_DEFAULT_PAGE_SIZE: %w", err)
+		}
+		cfg.DefaultPageSize = pageSize
+	}
+	if v := os.Getenv("ORA_AVAILABLE_LOCALES"); v != "" {
+		cfg.AvailableLocales = splitList(v)
+	}
+	if v := os.Getenv("ORA_OPENAPI_DOCS"); v != "" {
+		for _, pair := range splitList(v) {
+			name, url, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("ora: invalid env: ORA_OPENAPI_DOCS entry %q is not name=url", pair)
+			}
+			cfg.OpenapiDocs = append(cfg.OpenapiDocs, DocRef{Name: strings.TrimSpace(name), Url: strings.TrimSpace(url)})
+		}
+	}
+
+	if cfg.OpenapiDocUrl == "" && cfg.OpenapiDocFile == "" && len(cfg.OpenapiDocs) == 0 {
+		return nil, fmt.Errorf("ora: invalid env: one of ORA_OPENAPI_DOC_URL, ORA_OPENAPI_DOCS or ORA_OPENAPI_DOC_FILE is required")
+	}
+	return cfg, nil
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,44 @@
+package ora
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	faviconName = "favicon.svg"
+
+	// defaults matching the frontend, so the icon fits an unbranded admin
+	defaultAppTitle     = "OpenAPI Admin"
+	defaultPrimaryColor = "#1677ff"
+)
+
+// favicon draws the initials of AppTitle on PrimaryColor, so tabs of several admins tell apart
+func favicon(cfg *Config) []byte {
+	title, color := cfg.AppTitle, cfg.PrimaryColor
+	if strings.TrimSpace(title) == "" {
+		title = defaultAppTitle
+	}
+	if color == "" {
+		color = defaultPrimaryColor
+	}
+
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 64 64">`+
+		`<rect width="64" height="64" rx="12" fill="%s"/>`+
+		`<text x="32" y="33" fill="#fff" font-family="system-ui,-apple-system,Segoe UI,Roboto,sans-serif" font-size="28" font-weight="600" text-anchor="middle" dominant-baseline="central">%s</text>`+
+		`</svg>`, color, html.EscapeString(initials(title)))
+}
+
+// initials returns the upper cased first letters of the first two words of title
+func initials(title string) string {
+	words := strings.FieldsFunc(title, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var letters []rune
+	for _, word := range words[:min(len(words), 2)] {
+		r, _ := utf8.DecodeRuneInString(word)
+		letters = append(letters, unicode.ToUpper(r))
+	}
+	return string(letters)
+}
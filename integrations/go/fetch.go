@@ -0,0 +1,32 @@
+package ora
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// fetchContext bounds ctx by timeout, zero only keeps the bound of ctx
+func fetchContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// isTimeout reports whether err comes from a fetch that ran out of time
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout()
+}
+
+// upstreamError answers a request whose upstream fetch failed, 504 when it timed out and 502 otherwise
+func upstreamError(w http.ResponseWriter, err error, msg string) {
+	status := http.StatusBadGateway
+	if isTimeout(err) {
+		status = http.StatusGatewayTimeout
+	}
+	http.Error(w, msg, status)
+}
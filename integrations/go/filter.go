@@ -0,0 +1,144 @@
+package ora
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// operationKeys are the keys of a path item holding operations
+var operationKeys = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// FilterByTags returns a spec filter for WithSpecFilter removing the operations tagged with any of exclude,
+// along with the paths left without operations and the definitions of the excluded tags.
+// It only reads JSON documents, the order of their keys is kept.
+func FilterByTags(exclude ...string) func(spec []byte) ([]byte, error) {
+	excluded := func(tags []string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(exclude, tag) })
+	}
+
+	return func(spec []byte) ([]byte, error) {
+		if ext, _ := specFormat(spec); ext != ".json" {
+			return nil, errors.New("ora: FilterByTags: only JSON documents are supported")
+		}
+
+		doc, err := decodeObject(spec)
+		if err != nil {
+			return nil, fmt.Errorf("ora: FilterByTags: %w", err)
+		}
+
+		for i, m := range doc {
+			switch m.key {
+			case "paths":
+				doc[i].value, err = filterPaths(m.value, excluded)
+			case "tags":
+				doc[i].value, err = filterTags(m.value, excluded)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("ora: FilterByTags: %s: %w", m.key, err)
+			}
+		}
+		return encodeObject(doc), nil
+	}
+}
+
+func filterPaths(data json.RawMessage, excluded func([]string) bool) (json.RawMessage, error) {
+	paths, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := paths[:0]
+	for _, p := range paths {
+		item, err := decodeObject(p.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.key, err)
+		}
+
+		var ops, removed int
+		item = slices.DeleteFunc(item, func(m member) bool {
+			if !slices.Contains(operationKeys, m.key) {
+				return false
+			}
+			ops++
+
+			var op struct {
+				Tags []string `json:"tags"`
+			}
+			if json.Unmarshal(m.value, &op) == nil && excluded(op.Tags) {
+				removed++
+				return true
+			}
+			return false
+		})
+		if removed > 0 && removed == ops {
+			continue
+		}
+		kept = append(kept, member{key: p.key, value: encodeObject(item)})
+	}
+	return encodeObject(kept), nil
+}
+
+func filterTags(data json.RawMessage, excluded func([]string) bool) (json.RawMessage, error) {
+	var tags []json.RawMessage
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+
+	tags = slices.DeleteFunc(tags, func(t json.RawMessage) bool {
+		var tag struct {
+			Name string `json:"name"`
+		}
+		return json.Unmarshal(t, &tag) == nil && excluded([]string{tag.Name})
+	})
+	return json.Marshal(tags)
+}
+
+// member is a key of a JSON object, objects are kept as members to preserve their order
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+func decodeObject(data []byte) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("not a JSON object")
+	}
+
+	var members []member
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		m := member{key: t.(string)}
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func encodeObject(members []member) json.RawMessage {
+	buf := bytes.NewBufferString("{")
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(m.key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
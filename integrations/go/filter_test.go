@@ -0,0 +1,98 @@
+package ora
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const taggedSpec = `{"openapi":"3.0.0","info":{"title":"t"},` +
+	`"tags":[{"name":"public"},{"name":"internal","description":"ops"}],` +
+	`"paths":{` +
+	`"/users":{"parameters":[{"name":"org","in":"query"}],"get":{"tags":["public"]},"delete":{"tags":["internal"]}},` +
+	`"/debug":{"get":{"tags":["internal"]},"post":{"tags":["internal","public"]}},` +
+	`"/shared":{"$ref":"#/components/pathItems/shared"},` +
+	`"/untagged":{"get":{"summary":"x"}}},` +
+	`"components":{}}`
+
+func TestFilterByTags(t *testing.T) {
+	got, err := FilterByTags("internal")([]byte(taggedSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"openapi":"3.0.0","info":{"title":"t"},` +
+		`"tags":[{"name":"public"}],` +
+		`"paths":{` +
+		`"/users":{"parameters":[{"name":"org","in":"query"}],"get":{"tags":["public"]}},` +
+		`"/shared":{"$ref":"#/components/pathItems/shared"},` +
+		`"/untagged":{"get":{"summary":"x"}}},` +
+		`"components":{}}`
+	if string(got) != want {
+		t.Errorf("FilterByTags(internal) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFilterByTagsRejects(t *testing.T) {
+	for name, spec := range map[string]string{
+		"YAML":        "openapi: 3.0.0\npaths: {}\n",
+		"null paths":  `{"openapi":"3.0.0","paths":null}`,
+		"array paths": `{"openapi":"3.0.0","paths":[]}`,
+		"bad tags":    `{"openapi":"3.0.0","tags":{}}`,
+		"truncated":   `{"openapi":"3.0.0","paths":{`,
+	} {
+		if got, err := FilterByTags("internal")([]byte(spec)); err == nil {
+			t.Errorf("%s: FilterByTags = %s, want an error", name, got)
+		}
+	}
+}
+
+func TestSpecFilterThroughDocProxy(t *testing.T) {
+	const etag = `"v1"`
+	var hits, notModified atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(taggedSpec))
+	}))
+	defer upstream.Close()
+
+	var filtered atomic.Int64
+	filter := func(spec []byte) ([]byte, error) {
+		filtered.Add(1)
+		return FilterByTags("internal")(spec)
+	}
+	want, _ := FilterByTags("internal")([]byte(taggedSpec))
+
+	h := NewWithConfig(&Config{OpenapiDocUrl: upstream.URL, OpenapiDocProxyPath: "/openapi.json"}, WithSpecFilter(filter))
+	for range 3 {
+		if w := get(h, "/openapi.json"); w.Code != http.StatusOK || w.Body.String() != string(want) {
+			t.Fatalf("GET /openapi.json = %d %s, want the filtered document", w.Code, w.Body)
+		}
+	}
+	if hits.Load() != 1 || filtered.Load() != 1 {
+		t.Errorf("upstream hit %d times and filter ran %d times, want the filtered body cached", hits.Load(), filtered.Load())
+	}
+
+	// past the TTL the document is revalidated, and the 304 reuses the filtered body
+	p := &docProxy{url: upstream.URL, client: defaultClient, filter: filter, logger: slog.New(slog.DiscardHandler)}
+	filtered.Store(0)
+	if w := get(p, "/"); w.Body.String() != string(want) {
+		t.Fatalf("GET = %s, want the filtered document", w.Body)
+	}
+	p.doc.fetchedAt = time.Now().Add(-2 * docProxyTTL)
+	if w := get(p, "/"); w.Code != http.StatusOK || w.Body.String() != string(want) {
+		t.Errorf("revalidated GET = %d %s, want the filtered document", w.Code, w.Body)
+	}
+	if notModified.Load() != 1 || filtered.Load() != 1 {
+		t.Errorf("%d revalidations answered 304, filter ran %d times, want 1 and 1", notModified.Load(), filtered.Load())
+	}
+}
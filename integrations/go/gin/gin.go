@@ -0,0 +1,42 @@
+// Package oragin mounts the admin on a gin router.
+//
+//	cfg := &ora.Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json"}
+//	oragin.Mount(engine.Group("/admin"), cfg)
+package oragin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+// Mount registers the admin on cfg.Basename of r, it panics if the config is invalid
+//
+// r may be the engine itself or a group, the basename is resolved relative to
+// the group's base path so both Mount(engine, cfg) and Mount(engine.Group(cfg.Basename), cfg) work.
+// The basename is served by a catch-all route, so it should not be shared with other routes.
+func Mount(r gin.IRouter, cfg *ora.Config, opts ...ora.Option) {
+	h := gin.WrapH(ora.NewWithConfig(cfg, opts...))
+
+	rel := strings.TrimSuffix(ora.NormalizeBasename(cfg.Basename), "/")
+	if g, ok := r.(interface{ BasePath() string }); ok {
+		rel = strings.TrimPrefix(rel, strings.TrimRight(g.BasePath(), "/"))
+	}
+
+	// a catch-all on the root would collide with every other route,
+	// so let the engine hand us whatever it can't match instead
+	if e, ok := r.(*gin.Engine); ok && rel == "" {
+		e.NoRoute(func(c *gin.Context) {
+			c.Status(http.StatusOK) // gin presets 404 for unmatched routes
+			h(c)
+		})
+		return
+	}
+
+	// every method, so the API proxy gets the mutations
+	r.Any(rel, h)
+	r.Any(rel+"/*filepath", h)
+}
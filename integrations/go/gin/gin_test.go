@@ -0,0 +1,37 @@
+package oragin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+	"github.com/saltbo/openapi-rest-admin/integrations/go/internal/adaptertest"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// mounts are the ways of mounting the admin on a gin router
+var mounts = map[string]adaptertest.Mount{
+	"engine": func(cfg *ora.Config, opts ...ora.Option) http.Handler {
+		e := gin.New()
+		Mount(e, cfg, opts...)
+		return e
+	},
+	"group": func(cfg *ora.Config, opts ...ora.Option) http.Handler {
+		e := gin.New()
+		Mount(e.Group("/admin"), cfg, opts...)
+		return e
+	},
+}
+
+func TestMountProxiesMutations(t *testing.T) {
+	adaptertest.ProxiesMutations(t, mounts)
+}
+
+func TestMountServesTheShell(t *testing.T) {
+	adaptertest.ServesShell(t, mounts)
+}
@@ -1,3 +1,8 @@
 module github.com/saltbo/openapi-rest-admin/integrations/go
 
-go 1.16
+go 1.24.0
+
+require (
+	golang.org/x/sync v0.19.0
+	golang.org/x/text v0.34.0
+)
@@ -0,0 +1,106 @@
+package ora
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// allowedMethods are the methods the handler serves outside of the API proxy
+const allowedMethods = "GET, HEAD, OPTIONS"
+
+type handler struct {
+	index  *asset
+	assets map[string]*asset
+
+	// contentLanguage is sent with the index, from DefaultLocale
+	contentLanguage string
+
+	// the index is rendered per response when it carries a nonce
+	tmpl     *template.Template
+	page     page
+	encoders []encoder
+	security *security
+
+	docPath string
+	doc     http.Handler
+	version *asset
+	api     *apiProxy
+
+	logger *slog.Logger
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.security != nil {
+		h.security.setHeaders(w.Header())
+	}
+
+	p := path.Clean("/" + r.URL.Path)
+
+	// the backend answers its own OPTIONS, e.g. CORS preflights, and every other method
+	if h.api == nil || !h.api.match(p) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.Header().Set("Allow", allowedMethods)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Allow", allowedMethods)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+	}
+
+	if h.doc != nil && p == h.docPath {
+		setRoute(r, RouteDoc)
+		h.doc.ServeHTTP(w, r)
+		return
+	}
+	if h.version != nil && p == versionPath {
+		h.version.ServeHTTP(w, r)
+		return
+	}
+	if h.api != nil && h.api.match(p) {
+		setRoute(r, RouteProxy)
+		h.api.ServeHTTP(w, r)
+		return
+	}
+
+	name := strings.TrimPrefix(p, "/")
+	if a, ok := h.assets[name]; ok {
+		setRoute(r, RouteAsset)
+		a.ServeHTTP(w, r)
+		return
+	}
+
+	// paths that look like files must not fall back to the SPA,
+	// otherwise a missing bundle is answered with HTML
+	if path.Ext(name) != "" && name != indexName {
+		http.NotFound(w, r)
+		return
+	}
+
+	// everything else is a client-side route
+	setRoute(r, RouteShell)
+	if h.contentLanguage != "" {
+		w.Header().Set("Content-Language", h.contentLanguage)
+	}
+	if h.security != nil {
+		nonce := newNonce()
+		pg := h.page
+		pg.Nonce = nonce
+		body, err := render(h.tmpl, pg)
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "ora: render index failed", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", h.index.contentType)
+		h.security.serveIndex(w, r, nonce, body, h.encoders)
+		return
+	}
+	h.index.ServeHTTP(w, r)
+}
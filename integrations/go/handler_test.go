@@ -0,0 +1,84 @@
+package ora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMethodsOutsideTheAPIProxy(t *testing.T) {
+	h := NewWithConfig(&Config{OpenapiDocUrl: "/openapi.json"})
+
+	for _, tc := range []struct {
+		method string
+		want   int
+	}{
+		{http.MethodGet, http.StatusOK},
+		{http.MethodOptions, http.StatusNoContent},
+		{http.MethodPost, http.StatusMethodNotAllowed},
+		{http.MethodPut, http.StatusMethodNotAllowed},
+		{http.MethodPatch, http.StatusMethodNotAllowed},
+		{http.MethodDelete, http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, "/users", nil))
+		if w.Code != tc.want {
+			t.Errorf("%s /users = %d, want %d", tc.method, w.Code, tc.want)
+		}
+		if tc.want != http.StatusOK && w.Header().Get("Allow") != allowedMethods {
+			t.Errorf("%s /users Allow = %q, want %q", tc.method, w.Header().Get("Allow"), allowedMethods)
+		}
+	}
+}
+
+func TestHeadHasLengthButNoBody(t *testing.T) {
+	for name, h := range map[string]http.Handler{
+		"cached":   NewWithConfig(&Config{OpenapiDocUrl: "/openapi.json"}),
+		"with CSP": NewWithConfig(&Config{OpenapiDocUrl: "/openapi.json"}, WithSecurityHeaders()),
+	} {
+		full := get(h, "/")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: HEAD / = %d, want 200", name, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("%s: HEAD / wrote a %d byte body", name, w.Body.Len())
+		}
+		if got, want := w.Header().Get("Content-Length"), strconv.Itoa(full.Body.Len()); got != want {
+			t.Errorf("%s: HEAD / Content-Length = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSPAFallback(t *testing.T) {
+	assets := fstest.MapFS{
+		"index.html":               {Data: []byte(`<html><head></head><body><script>window.__ORA_CONFIG__ = '<<configuration>>';</script></body></html>`)},
+		"assets/index-Bk1lZ2xA.js": {Data: []byte("console.log(1)")},
+	}
+	h := NewWithConfig(&Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json"}, WithFS(assets))
+
+	for _, tc := range []struct {
+		target string
+		want   int
+		shell  bool
+	}{
+		{"/admin/", http.StatusOK, true},
+		{"/admin/assets/index-Bk1lZ2xA.js", http.StatusOK, false},
+		{"/admin/resources/users/42", http.StatusOK, true},
+		{"/admin/assets/missing-Bk1lZ2xA.js", http.StatusNotFound, false},
+	} {
+		w := get(h, tc.target)
+		if w.Code != tc.want {
+			t.Errorf("GET %s = %d, want %d", tc.target, w.Code, tc.want)
+			continue
+		}
+		if shell := strings.Contains(w.Body.String(), "window.__ORA_CONFIG__"); shell != tc.shell {
+			t.Errorf("GET %s served the shell = %v, want %v", tc.target, shell, tc.shell)
+		}
+	}
+}
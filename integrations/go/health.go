@@ -0,0 +1,129 @@
+package ora
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// healthTimeout bounds the reachability check of the OpenAPI document
+	healthTimeout = 2 * time.Second
+	// healthTTL is how long a check result is reused, so probes don't flood the spec server
+	healthTTL = 10 * time.Second
+)
+
+// HealthHandler returns a readiness endpoint answering 200 when the admin can serve a usable console,
+// and 503 with a JSON body describing the failed checks otherwise
+//
+// It checks that the frontend build has an index.html and, when OpenapiDocUrl is absolute,
+// that the document answers a HEAD request. Relative urls are served by the integrator and skipped.
+func HealthHandler(cfg *Config, opts ...Option) http.Handler {
+	return &health{o: newOptions(cfg, opts)}
+}
+
+type health struct {
+	o *options
+
+	mu        sync.Mutex
+	checks    map[string]string
+	checkedAt time.Time
+}
+
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (h *health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	checks := h.run(r.Context())
+
+	report := healthReport{Status: "ok", Checks: checks}
+	status := http.StatusOK
+	for _, result := range checks {
+		if result != "ok" {
+			report.Status, status = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// run returns the cached checks, or checks again once they are older than healthTTL.
+// A check isn't cut short by the probe that triggered it going away, it would be cached
+// as a failure for every probe after it, it is bounded by healthTimeout instead.
+func (h *health) run(ctx context.Context) map[string]string {
+	ctx = context.WithoutCancel(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.checks != nil && time.Since(h.checkedAt) < healthTTL {
+		return h.checks
+	}
+
+	checks := map[string]string{"assets": "ok"}
+	if _, err := h.o.readIndex(); err != nil {
+		checks["assets"] = err.Error()
+	}
+
+	c := &h.o.config
+	canceled := false
+	switch {
+	case c.OpenapiDocFile != "":
+		checks["openapiDoc"] = "ok"
+		if _, err := os.Stat(c.OpenapiDocFile); err != nil {
+			checks["openapiDoc"] = err.Error()
+		}
+	case isAbsoluteURL(c.OpenapiDocUrl):
+		checks["openapiDoc"] = "ok"
+		if err := h.reachable(ctx, c.OpenapiDocUrl); err != nil {
+			checks["openapiDoc"] = err.Error()
+			canceled = errors.Is(err, context.Canceled)
+		}
+	}
+
+	// a canceled check says nothing about the document, the next probe checks again
+	if !canceled {
+		h.checks, h.checkedAt = checks, time.Now()
+	}
+	return checks
+}
+
+func (h *health) reachable(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	status, err := h.probe(ctx, http.MethodHead, url)
+	if status == http.StatusMethodNotAllowed {
+		status, err = h.probe(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("%s answered %d", url, status)
+	}
+	return nil
+}
+
+func (h *health) probe(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := h.o.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
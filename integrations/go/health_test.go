@@ -0,0 +1,85 @@
+package ora
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// docServer serves an OpenAPI document, answering status while it is set
+type docServer struct {
+	*httptest.Server
+	hits   atomic.Int64
+	status atomic.Int64
+}
+
+func newDocServer(t *testing.T) *docServer {
+	t.Helper()
+	d := &docServer{}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.hits.Add(1)
+		if status := d.status.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		_, _ = w.Write([]byte(`{"openapi":"3.0.0"}`))
+	}))
+	t.Cleanup(d.Close)
+	return d
+}
+
+func probeHealth(t *testing.T, ctx context.Context, h http.Handler) (int, healthReport) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil).WithContext(ctx))
+	var report healthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("health body %q: %v", w.Body, err)
+	}
+	return w.Code, report
+}
+
+func TestHealthHandler(t *testing.T) {
+	doc := newDocServer(t)
+	h := HealthHandler(&Config{OpenapiDocUrl: doc.URL + "/openapi.json"})
+
+	for range 3 {
+		status, report := probeHealth(t, context.Background(), h)
+		if status != http.StatusOK || report.Status != "ok" || report.Checks["openapiDoc"] != "ok" || report.Checks["assets"] != "ok" {
+			t.Errorf("health = %d %+v, want 200 ok", status, report)
+		}
+	}
+	if n := doc.hits.Load(); n != 1 {
+		t.Errorf("document checked %d times, want once within healthTTL", n)
+	}
+}
+
+func TestHealthHandlerFailingDocument(t *testing.T) {
+	doc := newDocServer(t)
+	doc.status.Store(http.StatusInternalServerError)
+
+	status, report := probeHealth(t, context.Background(), HealthHandler(&Config{OpenapiDocUrl: doc.URL + "/openapi.json"}))
+	if status != http.StatusServiceUnavailable || report.Status != "unavailable" || report.Checks["openapiDoc"] == "ok" {
+		t.Errorf("health = %d %+v, want 503 with the failed openapiDoc check", status, report)
+	}
+	if report.Checks["assets"] != "ok" {
+		t.Errorf("assets check = %q, want ok", report.Checks["assets"])
+	}
+}
+
+func TestHealthHandlerCanceledProbe(t *testing.T) {
+	doc := newDocServer(t)
+	h := HealthHandler(&Config{OpenapiDocUrl: doc.URL + "/openapi.json"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if status, report := probeHealth(t, ctx, h); status != http.StatusOK {
+		t.Errorf("canceled probe = %d %+v, want the check to run to completion", status, report)
+	}
+	if status, report := probeHealth(t, context.Background(), h); status != http.StatusOK {
+		t.Errorf("probe after a canceled one = %d %+v, want 200", status, report)
+	}
+}
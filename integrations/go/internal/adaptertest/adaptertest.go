@@ -0,0 +1,73 @@
+// Package adaptertest holds the checks every router adapter of the admin has to pass,
+// so the gin and echo packages share them instead of each keeping a copy.
+package adaptertest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+// Mount returns a new router with the admin of cfg registered by the adapter under test
+type Mount func(cfg *ora.Config, opts ...ora.Option) http.Handler
+
+// ProxiesMutations checks that every mutating method reaches the upstream of the API proxy
+// mounted under /admin/api, with the basename and the proxy path stripped
+func ProxiesMutations(t *testing.T, mounts map[string]Mount) {
+	t.Helper()
+	var got []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	methods := []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	for name, mount := range mounts {
+		t.Run(name, func(t *testing.T) {
+			got = nil
+			h := mount(&ora.Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json", ApiProxyTarget: upstream.URL, ApiProxyPath: "/api"})
+
+			var want []string
+			for _, method := range methods {
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest(method, "/admin/api/users", nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("%s /admin/api/users = %d, want 200", method, w.Code)
+				}
+				want = append(want, method+" /users")
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("upstream got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// ServesShell checks that the bare basename, its slash form and the routes under it all serve
+// the index with the injected configuration. The basename is given with spaces and slashes around it,
+// the routes registered have to be normalized like the handler normalizes it.
+func ServesShell(t *testing.T, mounts map[string]Mount) {
+	t.Helper()
+	for name, mount := range mounts {
+		t.Run(name, func(t *testing.T) {
+			// without the redirect the bare basename is served as is
+			h := mount(&ora.Config{Basename: " admin/ ", OpenapiDocUrl: "/openapi.json"}, ora.WithTrailingSlashRedirect(false))
+
+			for _, target := range []string{"/admin", "/admin/", "/admin/anything"} {
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("GET %s = %d, want 200", target, w.Code)
+					continue
+				}
+				if body := w.Body.String(); !strings.Contains(body, `window.__ORA_CONFIG__ = {"basename":"/admin"`) {
+					t.Errorf("GET %s: no injected config in %q", target, body)
+				}
+			}
+		})
+	}
+}
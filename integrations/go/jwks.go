@@ -0,0 +1,188 @@
+package ora
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// jwksTTL is how long keys are trusted before the discovery document is read again
+	jwksTTL = time.Hour
+	// jwksMinRefresh limits refetching when tokens carry unknown key ids
+	jwksMinRefresh = time.Minute
+)
+
+var errUnknownKey = errors.New("ora: unknown signing key")
+
+// errKeysUnavailable wraps the failures to fetch the key set, as opposed to tokens that fail verification
+var errKeysUnavailable = errors.New("ora: signing keys unavailable")
+
+// keySet caches the signing keys of the issuer, following jwks_uri rotations
+type keySet struct {
+	issuer  string
+	client  *http.Client
+	timeout time.Duration
+
+	group singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	failure     error
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the key identified by kid, refreshing the set when
+// it is stale or doesn't know kid yet.
+//
+// Refreshes, failed ones included, happen at most every jwksMinRefresh and are shared by
+// the requests waiting on them, outside of the lock. When a refresh fails the keys already
+// known keep verifying tokens, even past jwksTTL.
+func (ks *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	k, known := ks.keys[kid]
+	fresh := time.Since(ks.fetchedAt) < jwksTTL
+	throttled := !ks.attemptedAt.IsZero() && time.Since(ks.attemptedAt) < jwksMinRefresh
+	ks.mu.Unlock()
+
+	if known && (fresh || throttled) {
+		return k, nil
+	}
+
+	if !throttled {
+		ch := ks.group.DoChan("keys", func() (any, error) {
+			return nil, ks.refresh(context.WithoutCancel(ctx))
+		})
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ks.mu.Lock()
+	k, ok := ks.keys[kid]
+	hasKeys := ks.keys != nil
+	failure := ks.failure
+	ks.mu.Unlock()
+	// a failed refresh leaves the known keys in place, a successful one drops the rotated out keys
+	switch {
+	case ok:
+		return k, nil
+	case !hasKeys && failure != nil:
+		return nil, fmt.Errorf("%w: %w", errKeysUnavailable, failure)
+	}
+	return nil, errUnknownKey
+}
+
+// refresh fetches the key set, the outcome is recorded so that failures are throttled too
+func (ks *keySet) refresh(ctx context.Context) (err error) {
+	// recorded once done, requests arriving meanwhile join this refresh instead of being throttled
+	defer func() {
+		ks.mu.Lock()
+		ks.attemptedAt, ks.failure = time.Now(), err
+		ks.mu.Unlock()
+	}()
+
+	ctx, cancel := fetchContext(ctx, ks.timeout)
+	defer cancel()
+
+	d, err := discover(ctx, ks.client, ks.issuer)
+	if err != nil {
+		return err
+	}
+	if d.JwksURI == "" {
+		return fmt.Errorf("ora: %s has no jwks_uri", ks.issuer)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, ks.client, d.JwksURI, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// keys we can't use are skipped rather than failing the whole set
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	ks.mu.Lock()
+	ks.keys, ks.fetchedAt = keys, time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("ora: unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("ora: unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
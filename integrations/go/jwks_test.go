@@ -0,0 +1,120 @@
+package ora
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// issuer serves a discovery document and a JWKS holding rsaKey under "k1" and ecKey under "k2",
+// until down is set
+type issuer struct {
+	*httptest.Server
+	rsaKey      *rsa.PrivateKey
+	ecKey       *ecdsa.PrivateKey
+	discoveries atomic.Int64
+	down        atomic.Bool
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iss := &issuer{rsaKey: rsaKey, ecKey: ecKey}
+	b64 := base64.RawURLEncoding.EncodeToString
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		iss.discoveries.Add(1)
+		if iss.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": iss.URL, "jwks_uri": iss.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{
+				"kid": "k1",
+				"kty": "RSA",
+				"n":   b64(rsaKey.N.Bytes()),
+				"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kid": "k2",
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		}})
+	})
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+func TestKeySetThrottlesFailedRefreshes(t *testing.T) {
+	iss := newIssuer(t)
+	iss.down.Store(true)
+	ks := &keySet{issuer: iss.URL, client: defaultClient, timeout: time.Second}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ks.key(context.Background(), "k1"); !errors.Is(err, errKeysUnavailable) {
+				t.Errorf("key() error = %v, want errKeysUnavailable", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := iss.discoveries.Load(); n != 1 {
+		t.Errorf("issuer hit %d times, want 1", n)
+	}
+}
+
+func TestKeySetServesStaleKeysWhenRefreshFails(t *testing.T) {
+	iss := newIssuer(t)
+	ks := &keySet{issuer: iss.URL, client: defaultClient, timeout: time.Second}
+	if _, err := ks.key(context.Background(), "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// past the TTL, with the issuer down
+	iss.down.Store(true)
+	ks.fetchedAt = time.Now().Add(-2 * jwksTTL)
+	ks.attemptedAt = ks.fetchedAt
+	for range 3 {
+		if _, err := ks.key(context.Background(), "k1"); err != nil {
+			t.Errorf("key() error = %v, want the stale key", err)
+		}
+	}
+	if n := iss.discoveries.Load(); n != 2 {
+		t.Errorf("issuer hit %d times, want 2", n)
+	}
+
+	if _, err := ks.key(context.Background(), "other"); !errors.Is(err, errUnknownKey) {
+		t.Errorf("key(other) error = %v, want errUnknownKey", err)
+	}
+}
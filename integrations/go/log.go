@@ -0,0 +1,85 @@
+package ora
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Route classifies the requests served by the handler
+type Route string
+
+const (
+	RouteShell Route = "shell" // the index.html, including client-side routes
+	RouteAsset Route = "asset" // the static files of the frontend build
+	RouteDoc   Route = "doc"   // the OpenAPI document, local or proxied
+	RouteProxy Route = "proxy" // the API proxy
+	RouteOther Route = "other" // anything else, e.g. missing files
+)
+
+// RequestInfo describes a request once it is served, see WithRequestHook
+type RequestInfo struct {
+	Method   string
+	Path     string
+	Route    Route
+	Status   int
+	Duration time.Duration
+}
+
+type requestInfoKey struct{}
+
+// setRoute records which route serves r
+func setRoute(r *http.Request, route Route) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*RequestInfo); ok {
+		info.Route = route
+	}
+}
+
+// observeRequests logs every request at debug level, and at error level when it fails,
+// then hands it to the request hooks
+func observeRequests(o *options, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info := &RequestInfo{Method: r.Method, Path: r.URL.Path, Route: RouteOther}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+		info.Status, info.Duration = rec.status, time.Since(start)
+
+		level := slog.LevelDebug
+		if info.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.Method),
+			slog.String("path", info.Path),
+			slog.String("route", string(info.Route)),
+			slog.Int("status", info.Status),
+			slog.Duration("duration", info.Duration),
+		}
+		if id := RequestIDFromContext(r.Context()); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		o.logger.LogAttrs(r.Context(), level, "ora: request", attrs...)
+
+		for _, hook := range o.requestHooks {
+			hook(*info)
+		}
+	})
+}
+
+// statusRecorder remembers the status written to the wrapped ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush proxied streams
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,71 @@
+// Package metrics collects Prometheus metrics for the admin handler.
+//
+//	m := metrics.New()
+//	prometheus.MustRegister(m.Collector())
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/admin/", ora.New(ora.WithBasename("/admin"), ora.WithOpenapiDocUrl("/openapi.json"), m.Option()))
+//	mux.Handle("/metrics", promhttp.Handler())
+//
+// It lives in its own package so that only users of it depend on Prometheus.
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	ora "github.com/saltbo/openapi-rest-admin/integrations/go"
+)
+
+// Metrics counts the requests served by the admin handlers it is an option of,
+// labeled by route class (shell, asset, doc, proxy, other) and status code
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// New returns Metrics named ora_requests_total and ora_request_duration_seconds
+func New() *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ora",
+			Name:      "requests_total",
+			Help:      "Requests served by the admin, by route class and status code.",
+		}, []string{"route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "ora",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve admin requests, by route class.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route"}),
+	}
+}
+
+// Option records the requests of the handler built with it
+func (m *Metrics) Option() ora.Option {
+	return ora.WithRequestHook(m.observe)
+}
+
+// Collector returns the collector to register
+func (m *Metrics) Collector() prometheus.Collector {
+	return m
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+func (m *Metrics) observe(info ora.RequestInfo) {
+	route := string(info.Route)
+	m.requests.WithLabelValues(route, strconv.Itoa(info.Status)).Inc()
+	m.duration.WithLabelValues(route).Observe(info.Duration.Seconds())
+}
@@ -0,0 +1,44 @@
+package ora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// discovery is the part of the OpenID provider metadata the package relies on
+type discovery struct {
+	Issuer             string `json:"issuer"`
+	JwksURI            string `json:"jwks_uri"`
+	EndSessionEndpoint string `json:"end_session_endpoint"`
+}
+
+func discover(ctx context.Context, client *http.Client, issuer string) (*discovery, error) {
+	var d discovery
+	u := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, u, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ora: fetch %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,302 @@
+package ora
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultClient is used for outbound calls unless WithHTTPClient is given, they are bounded
+// by the fetch timeout, the client's Timeout is a backstop for when it is turned off.
+// The API proxy only uses its Transport, so proxied requests aren't cut by it.
+var defaultClient = &http.Client{Timeout: time.Minute}
+
+// defaultFetchTimeout bounds the outbound calls unless WithFetchTimeout is given,
+// so a hung upstream doesn't pile up requests waiting on it
+const defaultFetchTimeout = 10 * time.Second
+
+// Option configures the handler built by New, NewWithConfig and NewHandler
+type Option func(*options)
+
+type options struct {
+	config       Config
+	client       *http.Client
+	fetchTimeout time.Duration
+	logger       *slog.Logger
+
+	requestHooks []func(RequestInfo)
+	auditHooks   []func(AuditEvent)
+	assets       fs.FS
+	spec         []byte
+	specFilter   func([]byte) ([]byte, error)
+
+	// the index template, when it isn't the index.html of assets
+	template     []byte
+	templateFS   fs.FS
+	templateName string
+
+	compression bool
+	encoders    []encoder
+	charset     string
+
+	oidcDiscovery bool
+
+	securityHeaders bool
+	csp             string
+
+	trailingSlashRedirect bool
+	versionEndpoint       bool
+}
+
+func newOptions(cfg *Config, opts []Option) *options {
+	o := &options{config: *cfg, client: defaultClient, logger: slog.New(slog.DiscardHandler), assets: dist, compression: true, encoders: []encoder{gzipEncoder}, charset: "utf-8", trailingSlashRedirect: true, fetchTimeout: defaultFetchTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithBasename sets the path the admin is mounted on
+func WithBasename(basename string) Option {
+	return func(o *options) {
+		o.config.Basename = basename
+	}
+}
+
+// WithPublicPath sets the path the browser sees the admin on, when a proxy mounts the basename elsewhere
+func WithPublicPath(publicPath string) Option {
+	return func(o *options) {
+		o.config.PublicPath = publicPath
+	}
+}
+
+// WithOpenapiDocUrl sets the url of the OpenAPI document
+func WithOpenapiDocUrl(url string) Option {
+	return func(o *options) {
+		o.config.OpenapiDocUrl = url
+	}
+}
+
+// WithAppTitle sets the title shown by the admin
+func WithAppTitle(title string) Option {
+	return func(o *options) {
+		o.config.AppTitle = title
+	}
+}
+
+// WithBuildInfo overrides the build stamped into the configuration, and served by WithVersionEndpoint,
+// e.g. with the version of your release instead of the one of this module
+func WithBuildInfo(info BuildInfo) Option {
+	return func(o *options) {
+		o.config.Build = &info
+	}
+}
+
+// WithVersionEndpoint toggles serving the build at Basename/__ora/version, it is off by default
+// so public deployments don't advertise the Go version and VCS revision of their binary.
+// The configuration of every page only carries the module version, unless WithBuildInfo sets more.
+func WithVersionEndpoint(enabled bool) Option {
+	return func(o *options) {
+		o.versionEndpoint = enabled
+	}
+}
+
+// WithOIDC enables the OIDC login of the admin
+func WithOIDC(issuer, clientID, redirectURI string) Option {
+	return func(o *options) {
+		o.config.OidcIssuer = issuer
+		o.config.OidcClientId = clientID
+		o.config.OidcRedirectUri = redirectURI
+	}
+}
+
+// WithFS serves the frontend build from assets instead of the embedded index loading it from the CDN,
+// see NewWithFS for the expected layout
+func WithFS(assets fs.FS) Option {
+	return func(o *options) {
+		o.assets = assets
+	}
+}
+
+// WithTemplate renders tmpl as the index instead of the index.html of the assets.
+// Like a custom index.html it is a html/template that must take the configuration
+// with the '<<configuration>>' placeholder or {{.Config}}, NewHandler fails otherwise.
+func WithTemplate(tmpl string) Option {
+	return func(o *options) {
+		o.template, o.templateFS = []byte(tmpl), nil
+	}
+}
+
+// WithTemplateFS is WithTemplate reading the template from the file name of fsys
+func WithTemplateFS(fsys fs.FS, name string) Option {
+	return func(o *options) {
+		o.template, o.templateFS, o.templateName = nil, fsys, name
+	}
+}
+
+// WithSpec serves spec as the OpenAPI document, see NewWithSpec
+func WithSpec(spec []byte) Option {
+	return func(o *options) {
+		o.spec = spec
+	}
+}
+
+// WithSpecFilter transforms the OpenAPI document before it is served, e.g. with FilterByTags
+// to hide internal operations. It applies to the documents the handler serves itself, proxied
+// or local, the result is cached along with them. A proxied document failing the filter
+// is treated like a failed fetch.
+func WithSpecFilter(filter func(spec []byte) ([]byte, error)) Option {
+	return func(o *options) {
+		o.specFilter = filter
+	}
+}
+
+// WithOIDCDiscovery makes the constructor read the issuer's discovery document
+// to fill OidcEndSessionEndpoint when it is empty
+func WithOIDCDiscovery() Option {
+	return func(o *options) {
+		o.oidcDiscovery = true
+	}
+}
+
+// WithHTTPClient sets the client shared by every outbound call, so they all go through the same transport:
+// fetching the proxied OpenAPI document, forwarding to the API proxy upstream (through its Transport),
+// OIDC discovery, JWKS retrieval and the health checks. They are bounded by WithFetchTimeout,
+// a Timeout of c applies on top of it.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithFetchTimeout bounds fetching the proxied OpenAPI document, OIDC discovery and JWKS retrieval,
+// 10s by default. The browser gets a 504 when one times out, a request that is canceled stops waiting
+// while the fetch goes on for the others sharing it. Zero or less leaves them bounded by the Timeout
+// of the client only, one minute for the default one, NewHandler fails for a client without any
+// when WithOIDCDiscovery would fetch at construction.
+func WithFetchTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.fetchTimeout = d
+	}
+}
+
+// WithCompression toggles serving compressed responses to clients accepting them, it is on by default
+func WithCompression(enabled bool) Option {
+	return func(o *options) {
+		o.compression = enabled
+	}
+}
+
+// WithCharset sets the charset the index is declared in by its Content-Type, utf-8 by default.
+// The index is sent as rendered, not transcoded, so the template and config must already be in it.
+func WithCharset(charset string) Option {
+	return func(o *options) {
+		o.charset = charset
+	}
+}
+
+// WithEncoder adds a content coding, like br, that bodies are precompressed with by encode at construction.
+// encodeFast compresses the shell on every response when security headers are on, as its nonce changes,
+// it should favor speed over ratio, nil uses encode.
+// Codings are preferred in reverse order of registration, so they all win over the builtin gzip,
+// and clients accepting none of them get the identity body.
+func WithEncoder(coding string, encode, encodeFast func(body []byte) ([]byte, error)) Option {
+	return func(o *options) {
+		if encodeFast == nil {
+			encodeFast = encode
+		}
+		o.encoders = append([]encoder{{coding: coding, encode: encode, encodeFast: encodeFast}}, o.encoders...)
+	}
+}
+
+// WithTrailingSlashRedirect toggles permanently redirecting the bare basename, e.g. /admin,
+// to /admin/ so relative asset URLs resolve under it. It is on by default and does nothing for "/".
+func WithTrailingSlashRedirect(enabled bool) Option {
+	return func(o *options) {
+		o.trailingSlashRedirect = enabled
+	}
+}
+
+// WithLogger sets the logger for requests, proxy failures and config warnings, nothing is logged by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRequestHook calls hook after every request, e.g. to collect metrics,
+// see the metrics subpackage for a Prometheus collector
+func WithRequestHook(hook func(RequestInfo)) Option {
+	return func(o *options) {
+		o.requestHooks = append(o.requestHooks, hook)
+	}
+}
+
+// WithAuditHook calls hook for every mutating request through the API proxy, in addition to
+// the "ora: audit" entries of the logger, e.g. to keep them in a compliance store
+func WithAuditHook(hook func(AuditEvent)) Option {
+	return func(o *options) {
+		o.auditHooks = append(o.auditHooks, hook)
+	}
+}
+
+// WithSecurityHeaders sends Content-Security-Policy, X-Frame-Options: DENY and X-Content-Type-Options: nosniff,
+// the default policy allows the index's scripts and styles, the OpenAPI document and the OIDC issuer
+func WithSecurityHeaders() Option {
+	return func(o *options) {
+		o.securityHeaders = true
+	}
+}
+
+// WithCSP enables the security headers with a custom Content-Security-Policy,
+// any "{nonce}" in the policy is replaced by the nonce given to the page's scripts
+func WithCSP(policy string) Option {
+	return func(o *options) {
+		o.securityHeaders = true
+		o.csp = policy
+	}
+}
+
+// readIndex returns the source of the index template
+func (o *options) readIndex() ([]byte, error) {
+	switch {
+	case o.template != nil:
+		return o.template, nil
+	case o.templateFS != nil:
+		src, err := fs.ReadFile(o.templateFS, o.templateName)
+		if err != nil {
+			return nil, fmt.Errorf("ora: read template: %w", err)
+		}
+		return src, nil
+	}
+
+	src, err := fs.ReadFile(o.assets, indexName)
+	if err != nil {
+		return nil, fmt.Errorf("ora: assets must contain %s: %w", indexName, err)
+	}
+	return src, nil
+}
+
+// contentEncoders returns the encoders bodies are compressed with, none when compression is off
+func (o *options) contentEncoders() []encoder {
+	if !o.compression {
+		return nil
+	}
+	return o.encoders
+}
+
+// warn logs the settings that are valid but likely not what was meant
+func (o *options) warn() {
+	if o.specFilter != nil && o.spec == nil && o.config.OpenapiDocFile == "" && o.config.OpenapiDocProxyPath == "" {
+		o.logger.Warn("ora: WithSpecFilter without OpenapiDocProxyPath or a local spec filters nothing, the browser fetches the documents itself")
+	}
+	if o.config.ReadOnly && o.config.ApiProxyTarget == "" {
+		o.logger.Warn("ora: ReadOnly without ApiProxyTarget only hides the actions in the frontend")
+	}
+	if o.csp != "" && !strings.Contains(o.csp, nonceToken) {
+		o.logger.Warn("ora: the custom CSP has no " + nonceToken + ", the inline config script may be blocked")
+	}
+}
@@ -1,25 +1,306 @@
+// Package ora serves the OpenAPI REST admin frontend, configured by a Config injected into its index.html.
+//
+// Handlers share no state, so several admins can be served by one server,
+// each mounted on its own basename with its own OIDC settings and OpenAPI documents:
+//
+//	mux := http.NewServeMux()
+//	for _, t := range tenants {
+//		mux.Handle(t.Basename+"/", ora.NewWithConfig(&ora.Config{
+//			Basename:      t.Basename,
+//			OpenapiDocUrl: t.DocUrl,
+//			OidcIssuer:    t.Issuer,
+//			OidcClientId:  t.ClientId,
+//		}))
+//	}
+//
+// The admin handles the whole subtree of its basename and answers 404 outside of it,
+// so the pattern registered on the mux must be the basename followed by a slash.
+//
+// The embedded build is only an index.html loading the frontend bundle from the jsDelivr CDN,
+// no /assets/* files are served by default. To serve the frontend from the handler itself,
+// e.g. without access to the CDN, pass the vite dist directory with NewWithFS or WithFS.
 package ora
 
 import (
-	_ "embed"
-	"encoding/json"
+	"cmp"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
 	"log"
 	"net/http"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-//go:embed assets/index.html
-var indexHTML string
+const indexName = "index.html"
 
-// New return a http.Handler
-func New(cfg *Config) http.Handler {
-	configuration, err := json.Marshal(cfg)
+// charsetName matches the charset names of RFC 2978
+var charsetName = regexp.MustCompile("^[A-Za-z0-9!#$%&'+^_`{}~-]+$")
+
+//go:embed assets
+var embedded embed.FS
+
+// dist is the embedded frontend build, laid out like the vite dist directory,
+// its index loads the bundle from the CDN so it holds no other file
+var dist, _ = fs.Sub(embedded, "assets")
+
+// New return a http.Handler built from the options, it panics if the config is invalid
+func New(opts ...Option) http.Handler {
+	return NewWithConfig(defaultConfig(), opts...)
+}
+
+// NewWithConfig return a http.Handler, it panics if the config is invalid
+func NewWithConfig(cfg *Config, opts ...Option) http.Handler {
+	h, err := NewHandler(cfg, opts...)
 	if err != nil {
 		log.Panic(err)
 	}
-	indexHTML = strings.ReplaceAll(indexHTML, "'<<configuration>>'", string(configuration))
-	return http.StripPrefix(cfg.Basename, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = w.Write([]byte(indexHTML))
-	}))
+	return h
+}
+
+// NewWithFS return a http.Handler serving the frontend build in assets, it panics if the config is invalid
+//
+// assets is laid out like the vite dist directory and must contain an index.html.
+// The index is parsed as a html/template, its configuration goes where the
+// '<<configuration>>' placeholder or {{.Config}} is, and {{.Nonce}} is the CSP nonce.
+// An index without either of them is invalid. WithTemplate replaces just the index.
+func NewWithFS(cfg *Config, assets fs.FS, opts ...Option) http.Handler {
+	return NewWithConfig(cfg, append([]Option{WithFS(assets)}, opts...)...)
+}
+
+// NewWithSpec return a http.Handler serving spec as the OpenAPI document, it panics if the config is invalid
+//
+// The spec, JSON or YAML, is served under the basename and the frontend is pointed at it,
+// so the admin works without reaching OpenapiDocUrl.
+func NewWithSpec(cfg *Config, spec []byte, opts ...Option) http.Handler {
+	return NewWithConfig(cfg, append([]Option{WithSpec(spec)}, opts...)...)
+}
+
+// Handler is the admin http.Handler, its config can be replaced while it serves
+type Handler struct {
+	opts []Option
+
+	mu      sync.Mutex
+	current atomic.Pointer[http.Handler]
+}
+
+// NewHandler return a *Handler, or an error if the config is invalid
+//
+// New, NewWithConfig, NewWithFS and NewWithSpec return a *Handler too,
+// it can be recovered with a type assertion to call Update.
+func NewHandler(cfg *Config, opts ...Option) (*Handler, error) {
+	h := &Handler{opts: opts}
+	if err := h.Update(cfg); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load()).ServeHTTP(w, r)
+}
+
+// Update rebuilds the handler from cfg and the options it was created with, which still apply on top of cfg.
+// Requests in flight finish with the previous config, the next ones see cfg.
+// When cfg is invalid the error is returned and the previous config keeps being served.
+func (h *Handler) Update(cfg *Config) error {
+	// concurrent updates are applied in order, so the last one wins
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	next, err := build(cfg, h.opts)
+	if err != nil {
+		return err
+	}
+	h.current.Store(&next)
+	return nil
+}
+
+func build(cfg *Config, opts []Option) (http.Handler, error) {
+	if cfg == nil {
+		return nil, errNilConfig
+	}
+
+	o := newOptions(cfg, opts)
+	h, err := newHandler(o)
+	if err != nil {
+		return nil, err
+	}
+
+	o.warn()
+	redirect := ""
+	if o.trailingSlashRedirect {
+		redirect = strings.TrimSuffix(o.config.PublicPath, "/") + "/"
+	}
+	return observeRequests(o, stripBasename(o.config.Basename, redirect, h)), nil
+}
+
+// Render return the index New serves for cfg and opts, as rendered without a nonce,
+// e.g. for golden files or to embed the admin in a page of your own
+func Render(cfg *Config, opts ...Option) ([]byte, error) {
+	if cfg == nil {
+		return nil, errNilConfig
+	}
+
+	h, err := newHandler(newOptions(cfg, opts))
+	if err != nil {
+		return nil, err
+	}
+	return h.index.body, nil
+}
+
+// newHandler builds the handler serving o, the basename is stripped before it
+func newHandler(o *options) (*handler, error) {
+	o.config.Basename = NormalizeBasename(o.config.Basename)
+	o.config.PublicPath = NormalizeBasename(cmp.Or(o.config.PublicPath, o.config.Basename))
+	if o.oidcDiscovery && o.config.OidcIssuer != "" && o.config.OidcEndSessionEndpoint == "" {
+		// nothing cancels a fetch made at construction, it must be bounded by something
+		if o.fetchTimeout <= 0 && o.client.Timeout <= 0 {
+			return nil, fmt.Errorf("ora: WithOIDCDiscovery needs a fetch timeout or a client Timeout")
+		}
+		ctx, cancel := fetchContext(context.Background(), o.fetchTimeout)
+		d, err := discover(ctx, o.client, o.config.OidcIssuer)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("ora: discover %s: %w", o.config.OidcIssuer, err)
+		}
+		o.config.OidcEndSessionEndpoint = d.EndSessionEndpoint
+	}
+	if err := o.config.validate(); err != nil {
+		return nil, err
+	}
+
+	docPath, doc, err := newDocRoute(o)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := loadAssets(o.assets, o.contentEncoders())
+	if err != nil {
+		return nil, err
+	}
+
+	// the frontend sees the local path, the handler keeps the upstream,
+	// and local paths are where the browser sees the admin
+	injected := o.config
+	injected.Basename = o.config.PublicPath
+
+	// without a favicon of its own the admin gets one generated from its title and color
+	if injected.FaviconUrl == "" && assets[faviconName] == nil {
+		icon, err := newAsset("image/svg+xml", cacheNoCache, favicon(&o.config), o.contentEncoders())
+		if err != nil {
+			return nil, err
+		}
+		assets[faviconName] = icon
+	}
+	if injected.FaviconUrl == "" {
+		injected.FaviconUrl = path.Join(o.config.PublicPath, faviconName)
+	}
+	if doc != nil {
+		injected.OpenapiDocUrl = path.Join(o.config.PublicPath, docPath)
+	}
+
+	// every page only gets the module version, the stamps of the binary are for the opt-in endpoint
+	if injected.Build == nil {
+		injected.Build = &BuildInfo{Version: defaultBuildInfo().Version}
+	}
+
+	if injected.OidcIssuer != "" && injected.OidcUsePKCE == nil {
+		usePKCE := injected.isCodeFlow()
+		injected.OidcUsePKCE = &usePKCE
+	}
+
+	// frontends without the switcher still open the first document
+	if injected.OpenapiDocUrl == "" && len(injected.OpenapiDocs) > 0 {
+		injected.OpenapiDocUrl = injected.OpenapiDocs[0].Url
+	}
+
+	configuration, err := injected.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	indexHTML, err := o.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := parseIndex(indexHTML)
+	if err != nil {
+		return nil, err
+	}
+
+	// without a nonce the shell is the same for every response, so render it once,
+	// it is revalidated on every load so config changes show up immediately
+	pg := page{Config: configuration, Base: strings.TrimSuffix(o.config.PublicPath, "/") + "/", Head: template.HTML(o.config.HeadHTML)}
+	html, err := render(tmpl, pg)
+	if err != nil {
+		return nil, err
+	}
+	if !charsetName.MatchString(o.charset) {
+		return nil, fmt.Errorf("ora: invalid charset %q", o.charset)
+	}
+	index, err := newAsset("text/html; charset="+o.charset, cacheNoCache, html, o.contentEncoders())
+	if err != nil {
+		return nil, err
+	}
+
+	h := &handler{index: index, assets: assets, contentLanguage: o.config.DefaultLocale}
+	if o.securityHeaders {
+		policy := o.csp
+		if policy == "" {
+			policy = defaultCSP(&o.config, slices.Concat(indexHTML, []byte(o.config.HeadHTML)))
+		}
+		h.tmpl, h.page, h.encoders = tmpl, pg, o.contentEncoders()
+		h.security = &security{policy: policy}
+	}
+	h.docPath, h.doc = docPath, doc
+	if o.versionEndpoint {
+		info := o.config.Build
+		if info == nil {
+			full := defaultBuildInfo()
+			info = &full
+		}
+		if h.version, err = newVersionAsset(info); err != nil {
+			return nil, err
+		}
+	}
+	if o.config.ApiProxyTarget != "" {
+		h.api = newAPIProxy(o)
+	}
+
+	h.logger = o.logger
+	return h, nil
+}
+
+// stripBasename is http.StripPrefix that only matches whole path segments,
+// so "/admin" doesn't serve "/administrator". When redirect is set, the bare basename
+// is redirected to it, the slash form as the browser sees it, so relative URLs resolve under it.
+func stripBasename(basename, redirect string, h http.Handler) http.Handler {
+	if basename == "/" {
+		return h
+	}
+
+	strip := http.StripPrefix(basename, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != basename && !strings.HasPrefix(r.URL.Path, basename+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		if redirect != "" && r.URL.Path == basename {
+			target := redirect
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+			return
+		}
+		strip.ServeHTTP(w, r)
+	})
 }
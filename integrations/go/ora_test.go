@@ -0,0 +1,196 @@
+package ora
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// configOf extracts the configuration injected into a rendered index
+func configOf(t *testing.T, body string) map[string]any {
+	t.Helper()
+	m := regexp.MustCompile(`window\.__ORA_CONFIG__ = (.*?);</script>`).FindStringSubmatch(body)
+	if m == nil {
+		t.Fatalf("no injected config in %q", body)
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal([]byte(m[1]), &cfg); err != nil {
+		t.Fatalf("injected config %s: %v", m[1], err)
+	}
+	return cfg
+}
+
+func get(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestMultipleTenantsOnOneMux(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, tenant := range []string{"a", "b"} {
+		mux.Handle("/tenant-"+tenant+"/", NewWithConfig(&Config{
+			Basename:      "/tenant-" + tenant,
+			OpenapiDocUrl: "https://" + tenant + ".example.com/openapi.json",
+			OidcIssuer:    "https://idp-" + tenant + ".example.com",
+			OidcClientId:  "client-" + tenant,
+		}))
+	}
+
+	for _, tc := range []struct{ path, tenant string }{
+		{"/tenant-a/", "a"},
+		{"/tenant-a/users/1", "a"},
+		{"/tenant-b/", "b"},
+		{"/tenant-b/orders", "b"},
+	} {
+		w := get(mux, tc.path)
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", tc.path, w.Code)
+			continue
+		}
+		cfg := configOf(t, w.Body.String())
+		if cfg["basename"] != "/tenant-"+tc.tenant || cfg["oidcClientId"] != "client-"+tc.tenant ||
+			cfg["openapiDocUrl"] != "https://"+tc.tenant+".example.com/openapi.json" {
+			t.Errorf("GET %s served %v, want the config of tenant %s", tc.path, cfg, tc.tenant)
+		}
+	}
+
+	if w := get(mux, "/tenant-c/"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /tenant-c/ = %d, want 404", w.Code)
+	}
+}
+
+func TestPublicPathBehindRewritingProxy(t *testing.T) {
+	assets := fstest.MapFS{
+		"index.html":               {Data: []byte(`<html><head><script type="module" src="assets/index-Bk1lZ2xA.js"></script></head><body><script>window.__ORA_CONFIG__ = '<<configuration>>';</script></body></html>`)},
+		"assets/index-Bk1lZ2xA.js": {Data: []byte("console.log(1)")},
+	}
+	// the proxy strips /tools from what the browser requests
+	h := NewWithConfig(&Config{Basename: "/admin", PublicPath: "/tools/admin", OpenapiDocUrl: "/openapi.json"}, WithFS(assets))
+
+	for _, target := range []string{"/admin/", "/admin/users/1"} {
+		w := get(h, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d, want 200", target, w.Code)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, `<head><base href="/tools/admin/" />`) {
+			t.Errorf("GET %s: no <base> of the public path in %q", target, body)
+		}
+		if got := configOf(t, body)["basename"]; got != "/tools/admin" {
+			t.Errorf("GET %s: basename = %v, want /tools/admin", target, got)
+		}
+	}
+
+	if w := get(h, "/admin/assets/index-Bk1lZ2xA.js"); w.Code != http.StatusOK {
+		t.Errorf("asset under the mount path = %d, want 200", w.Code)
+	}
+	if w := get(h, "/tools/admin/"); w.Code != http.StatusNotFound {
+		t.Errorf("GET under the public path = %d, want 404, the proxy strips it", w.Code)
+	}
+	if w := get(h, "/admin"); w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/tools/admin/" {
+		t.Errorf("GET /admin = %d to %q, want a redirect to /tools/admin/", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestTemplateBaseIsKept(t *testing.T) {
+	tmpl := `<html><head><base href="/static/"></head><body><script>window.__ORA_CONFIG__ = {{.Config}};</script></body></html>`
+	body := get(NewWithConfig(&Config{PublicPath: "/tools", OpenapiDocUrl: "/openapi.json"}, WithTemplate(tmpl)), "/").Body.String()
+	if n := strings.Count(body, "<base"); n != 1 || !strings.Contains(body, `<base href="/static/">`) {
+		t.Errorf("template <base> replaced or doubled: %q", body)
+	}
+}
+
+func TestUpdateUnderLoad(t *testing.T) {
+	h, err := NewHandler(&Config{OpenapiDocUrl: "/openapi.json", AppTitle: "v0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const updates = 50
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				w := get(h, "/users")
+				if w.Code != http.StatusOK {
+					t.Errorf("GET /users = %d during updates, want 200", w.Code)
+					return
+				}
+				// configOf can't be used here, it stops the test from outside of its goroutine
+				if !strings.Contains(w.Body.String(), `"appTitle":"v`) {
+					t.Errorf("GET /users served %q during updates, want one of the configs", w.Body.String())
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= updates; i++ {
+		if err := h.Update(&Config{OpenapiDocUrl: "/openapi.json", AppTitle: "v" + strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	want := "v" + strconv.Itoa(updates)
+	if got := configOf(t, get(h, "/").Body.String())["appTitle"]; got != want {
+		t.Errorf("appTitle after the updates = %v, want %s", got, want)
+	}
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	cfg := &Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json"}
+	h := NewWithConfig(cfg)
+
+	for _, tc := range []struct{ target, location string }{
+		{"/admin", "/admin/"},
+		{"/admin?tab=users", "/admin/?tab=users"},
+	} {
+		w := get(h, tc.target)
+		if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != tc.location {
+			t.Errorf("GET %s = %d to %q, want 308 to %s", tc.target, w.Code, w.Header().Get("Location"), tc.location)
+		}
+	}
+	if w := get(h, "/admin/"); w.Code != http.StatusOK {
+		t.Errorf("GET /admin/ = %d, want 200", w.Code)
+	}
+
+	if w := get(NewWithConfig(cfg, WithTrailingSlashRedirect(false)), "/admin"); w.Code != http.StatusOK {
+		t.Errorf("GET /admin without the redirect = %d, want 200", w.Code)
+	}
+	if w := get(NewWithConfig(&Config{Basename: "/", OpenapiDocUrl: "/openapi.json"}), "/"); w.Code != http.StatusOK {
+		t.Errorf("GET / of the root basename = %d, want 200", w.Code)
+	}
+}
+
+func TestDiscoveryIsAlwaysBounded(t *testing.T) {
+	iss := newIssuer(t)
+	cfg := &Config{OpenapiDocUrl: "/openapi.json", OidcIssuer: iss.URL, OidcClientId: "admin"}
+
+	if _, err := NewHandler(cfg, WithOIDCDiscovery(), WithFetchTimeout(0), WithHTTPClient(&http.Client{})); err == nil {
+		t.Error("NewHandler accepted an unbounded discovery")
+	}
+	// the default client's Timeout is the backstop
+	if _, err := NewHandler(cfg, WithOIDCDiscovery(), WithFetchTimeout(0)); err != nil {
+		t.Errorf("NewHandler with the default client = %v", err)
+	}
+	if _, err := NewHandler(cfg, WithOIDCDiscovery(), WithHTTPClient(&http.Client{})); err != nil {
+		t.Errorf("NewHandler with the fetch timeout = %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package ora
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitSweep is how often idle buckets are dropped
+const rateLimitSweep = time.Minute
+
+// RateLimitMiddleware allows each client IP rps requests per second with bursts of burst,
+// requests above it are answered with 429 and a Retry-After header.
+// It panics if rps isn't positive or burst is less than 1, which would reject every request.
+func RateLimitMiddleware(rps float64, burst int) func(http.Handler) http.Handler {
+	return RateLimitMiddlewareFunc(rps, burst, ClientIP)
+}
+
+// RateLimitMiddlewareFunc is RateLimitMiddleware keyed by key instead of the client IP,
+// e.g. SubjectOrClientIP to limit authenticated users individually, it panics like RateLimitMiddleware
+func RateLimitMiddlewareFunc(rps float64, burst int, key func(*http.Request) string) func(http.Handler) http.Handler {
+	if !(rps > 0) || math.IsInf(rps, 1) {
+		panic("ora: RateLimitMiddleware requires a positive finite rps")
+	}
+	if burst < 1 {
+		panic("ora: RateLimitMiddleware requires a burst of at least 1")
+	}
+	l := &rateLimiter{rps: rps, burst: float64(burst), key: key, buckets: make(map[string]*bucket)}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait, ok := l.allow(l.key(r), time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the IP the request comes from, forwarded headers are not trusted
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SubjectOrClientIP returns the subject validated by AuthMiddleware, or the client IP without one
+func SubjectOrClientIP(r *http.Request) string {
+	if sub := ClaimsFromContext(r.Context()).Subject(); sub != "" {
+		return "sub:" + sub
+	}
+	return ClientIP(r)
+}
+
+// rateLimiter keeps a token bucket per key
+type rateLimiter struct {
+	rps   float64
+	burst float64
+	key   func(*http.Request) string
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token from the bucket of key, or tells how long until one is available
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.sweptAt) >= rateLimitSweep {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rps * float64(time.Second)), false
+	}
+
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops the buckets that are full again, they behave like new ones
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rps >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.sweptAt = now
+}
@@ -0,0 +1,106 @@
+package ora
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddlewareRejectsInvalidLimits(t *testing.T) {
+	for _, tc := range []struct {
+		rps   float64
+		burst int
+	}{
+		{0, 1},
+		{-1, 1},
+		{math.NaN(), 1},
+		{math.Inf(1), 1},
+		{1, 0},
+		{1, -1},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimitMiddleware(%v, %d) didn't panic", tc.rps, tc.burst)
+				}
+			}()
+			RateLimitMiddleware(tc.rps, tc.burst)
+		}()
+	}
+
+	RateLimitMiddleware(0.5, 1)
+}
+
+func newTestLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{rps: rps, burst: float64(burst), key: ClientIP, buckets: make(map[string]*bucket)}
+}
+
+func TestRateLimiterBucket(t *testing.T) {
+	l := newTestLimiter(2, 3)
+	now := time.Unix(1_000_000, 0)
+
+	for i := range 3 {
+		if _, ok := l.allow("a", now); !ok {
+			t.Fatalf("request %d of the burst denied", i+1)
+		}
+	}
+	if wait, ok := l.allow("a", now); ok || wait != 500*time.Millisecond {
+		t.Errorf("past the burst: allow = %v, %v, want denied for 500ms", wait, ok)
+	}
+	if _, ok := l.allow("b", now); !ok {
+		t.Error("other key denied, buckets must be separate")
+	}
+
+	// 2 rps refill one token every 500ms
+	now = now.Add(500 * time.Millisecond)
+	if _, ok := l.allow("a", now); !ok {
+		t.Error("refilled token denied")
+	}
+	if wait, ok := l.allow("a", now); ok || wait != 500*time.Millisecond {
+		t.Errorf("after the refill: allow = %v, %v, want denied for 500ms", wait, ok)
+	}
+	now = now.Add(10 * time.Second)
+	for i := range 3 {
+		if _, ok := l.allow("a", now); !ok {
+			t.Fatalf("request %d after a full refill denied, the bucket holds burst tokens", i+1)
+		}
+	}
+	if _, ok := l.allow("a", now); ok {
+		t.Error("refill exceeded the burst")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := newTestLimiter(0.02, 3)
+	now := time.Unix(1_000_000, 0)
+	l.allow("idle", now)
+	l.allow("busy", now)
+	for range 2 {
+		l.allow("busy", now)
+	}
+
+	// a sweep period gives back 1.2 tokens, enough for idle to be full again, not busy
+	l.allow("new", now.Add(rateLimitSweep))
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket kept by the sweep")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("busy bucket dropped by the sweep, it would get a full burst back")
+	}
+}
+
+func TestRateLimitMiddlewareRetryAfter(t *testing.T) {
+	h := RateLimitMiddleware(0.5, 1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != want {
+			t.Errorf("request %d = %d, want %d", i+1, w.Code, want)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "2" {
+			t.Errorf("Retry-After = %q, want 2", w.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ora
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// page is the data the index template is rendered with
+type page struct {
+	// Config is the configuration handed to the frontend as window.__ORA_CONFIG__
+	Config json.RawMessage
+	// Nonce is set on the inline scripts when a Content-Security-Policy is sent
+	Nonce string
+	// Base is the PublicPath with a trailing slash, for <base href>
+	Base string
+	// Head is Config.HeadHTML, emitted as is at the end of <head>
+	Head template.HTML
+}
+
+// placeholder marks where custom index.html files expect the configuration
+const placeholder = "'<<configuration>>'"
+
+// headEnd is where templates not placing {{.Head}} themselves get it
+var headEnd = regexp.MustCompile(`(?i)</head>`)
+
+// headStart is where templates without a <base> of their own get one
+var headStart = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+
+// baseTag matches a <base> element a template sets itself
+var baseTag = regexp.MustCompile(`(?i)<base[\s/>]`)
+
+// sentinel is rendered as the configuration to check that a template takes it
+var sentinel = json.RawMessage(`{"__ora_sentinel__":true}`)
+
+func parseIndex(src []byte) (*template.Template, error) {
+	// the placeholder becomes a template action, so the config is escaped
+	// instead of being pasted into the page
+	text := strings.ReplaceAll(string(src), placeholder, "{{.Config}}")
+	tmpl, err := template.New(indexName).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("ora: parse template: %w", err)
+	}
+	injected := text
+	if !usesField(tmpl, "Head") {
+		if loc := headEnd.FindStringIndex(injected); loc != nil {
+			injected = injected[:loc[0]] + "{{.Head}}" + injected[loc[0]:]
+		}
+	}
+	// relative asset URLs resolve against the PublicPath, not the path the page was loaded from
+	if !usesField(tmpl, "Base") && !baseTag.MatchString(injected) {
+		if loc := headStart.FindStringIndex(injected); loc != nil {
+			injected = injected[:loc[1]] + `<base href="{{.Base}}" />` + injected[loc[1]:]
+		}
+	}
+	if injected != text {
+		if tmpl, err = template.New(indexName).Parse(injected); err != nil {
+			return nil, fmt.Errorf("ora: parse template: %w", err)
+		}
+	}
+
+	// a template without the config renders a frontend that can't start, fail now instead
+	html, err := render(tmpl, page{Config: sentinel})
+	if err != nil {
+		return nil, fmt.Errorf("ora: render template: %w", err)
+	}
+	if !bytes.Contains(html, sentinel) {
+		return nil, fmt.Errorf("ora: template must contain the %s placeholder or {{.Config}} in a script", placeholder)
+	}
+	return tmpl, nil
+}
+
+// usesField reports whether an action of tmpl, or of the templates it defines, reads the page field
+func usesField(tmpl *template.Template, field string) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && nodeUses(t.Tree.Root, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeUses(node parse.Node, field string) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		return slices.ContainsFunc(n.Nodes, func(c parse.Node) bool { return nodeUses(c, field) })
+	case *parse.ActionNode:
+		return nodeUses(n.Pipe, field)
+	case *parse.IfNode:
+		return nodeUses(&n.BranchNode, field)
+	case *parse.RangeNode:
+		return nodeUses(&n.BranchNode, field)
+	case *parse.WithNode:
+		return nodeUses(&n.BranchNode, field)
+	case *parse.BranchNode:
+		return nodeUses(n.Pipe, field) || nodeUses(n.List, field) || nodeUses(n.ElseList, field)
+	case *parse.TemplateNode:
+		return nodeUses(n.Pipe, field)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if slices.ContainsFunc(cmd.Args, func(a parse.Node) bool { return nodeUses(a, field) }) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return nodeUses(n.Node, field)
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == field
+	}
+	return false
+}
+
+// render executes the index template, html/template escapes the config for the script context,
+// the {nonce} of the head tags is replaced with p.Nonce so their inline scripts can carry it
+func render(tmpl *template.Template, p page) ([]byte, error) {
+	p.Head = template.HTML(strings.ReplaceAll(string(p.Head), nonceToken, p.Nonce))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
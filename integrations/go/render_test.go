@@ -0,0 +1,43 @@
+package ora
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadHTMLInCustomTemplate(t *testing.T) {
+	// .Header and res.Headers mentioned by the template are not the {{.Head}} action
+	tmpl := `<!doctype html><html><head><style>.Header{color:red}</style>
+<script>fetch("/x").then(res => res.Headers)</script></head>
+<body><script>window.__ORA_CONFIG__ = '<<configuration>>';</script></body></html>`
+	h := NewWithConfig(&Config{
+		OpenapiDocUrl: "https://api.example.com/openapi.json",
+		AppTitle:      "</script><script>alert(1)</script>",
+		HeadHTML:      `<meta name="snippet" content="1">`,
+	}, WithTemplate(tmpl))
+
+	w := get(h, "/")
+	body := w.Body.String()
+	if !strings.Contains(body, `<meta name="snippet" content="1"></head>`) {
+		t.Errorf("head snippet missing from %q", body)
+	}
+	if strings.Contains(body, "<script>alert(1)") {
+		t.Errorf("config not escaped in %q", body)
+	}
+	if got := configOf(t, body)["appTitle"]; got != "</script><script>alert(1)</script>" {
+		t.Errorf("appTitle = %v, want the unescaped title", got)
+	}
+}
+
+func TestHeadHTMLPlacedByTemplate(t *testing.T) {
+	tmpl := `<html><head><title>x</title></head><body>{{.Head}}<script>window.__ORA_CONFIG__ = {{.Config}};</script></body></html>`
+	h := NewWithConfig(&Config{OpenapiDocUrl: "/openapi.json", HeadHTML: "<i>snippet</i>"}, WithTemplate(tmpl))
+
+	body := get(h, "/").Body.String()
+	if n := strings.Count(body, "<i>snippet</i>"); n != 1 {
+		t.Errorf("snippet emitted %d times, want once in %q", n, body)
+	}
+	if strings.Contains(body, "<i>snippet</i></head>") {
+		t.Errorf("snippet injected before </head> although the template places it: %q", body)
+	}
+}
@@ -0,0 +1,68 @@
+package ora
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// requestIDHeader carries the correlation ID of a request, from the client through the API proxy to the backend
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds the incoming IDs kept, longer ones are replaced
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware gives every request an ID, the incoming X-Request-ID or a generated UUID,
+// stores it in the request context and echoes it in the X-Request-ID response header.
+//
+// The API proxy forwards it upstream and the request and audit logs include it,
+// wrap the admin handler with it to correlate a page load across them:
+//
+//	http.Handle("/admin/", ora.RequestIDMiddleware()(ora.New(ora.WithBasename("/admin"))))
+func RequestIDMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+			r.Header.Set(requestIDHeader, id)
+			w.Header().Set(requestIDHeader, id)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestIDFromContext returns the ID RequestIDMiddleware gave the request, empty without it
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether id can be kept as is, it ends up in logs and upstream headers
+// so only short printable ASCII is
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random (version 4) UUID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
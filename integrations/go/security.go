@@ -0,0 +1,114 @@
+package ora
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// nonceToken is replaced by the per-response nonce in the Content-Security-Policy
+const nonceToken = "{nonce}"
+
+// externalOrigin matches the origins the index loads scripts and styles from
+var externalOrigin = regexp.MustCompile(`(?:src|href)="(https?://[^"/]+)`)
+
+type security struct {
+	policy string
+}
+
+// defaultCSP permits the index's own scripts and styles, the nonce'd inline config,
+// and the origins of the OpenAPI documents and the OIDC issuer
+func defaultCSP(cfg *Config, index []byte) string {
+	var assetOrigins []string
+	for _, m := range externalOrigin.FindAllSubmatch(index, -1) {
+		assetOrigins = appendUnique(assetOrigins, string(m[1]))
+	}
+
+	connectOrigins := []string{"'self'"}
+	var frameOrigins []string
+	urls := []string{cfg.OpenapiDocUrl, cfg.OidcIssuer}
+	for _, doc := range cfg.OpenapiDocs {
+		urls = append(urls, doc.Url)
+	}
+	for _, u := range urls {
+		if origin := originOf(u); origin != "" {
+			connectOrigins = appendUnique(connectOrigins, origin)
+		}
+	}
+	if origin := originOf(cfg.OidcIssuer); origin != "" {
+		frameOrigins = append(frameOrigins, origin) // silent renew runs in an iframe
+	}
+
+	directives := []string{
+		"default-src 'self'",
+		join("script-src 'self' 'nonce-"+nonceToken+"'", assetOrigins),
+		join("style-src 'self' 'unsafe-inline'", assetOrigins),
+		join("font-src 'self' data:", assetOrigins),
+		"img-src 'self' data: https:",
+		join("connect-src", connectOrigins),
+		join("frame-src 'self'", frameOrigins),
+		"frame-ancestors 'none'",
+		"base-uri 'self'",
+		"object-src 'none'",
+	}
+	return strings.Join(directives, "; ")
+}
+
+// setHeaders sets the headers sent with every response
+func (s *security) setHeaders(h http.Header) {
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("X-Frame-Options", "DENY")
+}
+
+// serveIndex serves body, rendered with nonce, along with the policy allowing it,
+// the Content-Type is set by the caller
+func (s *security) serveIndex(w http.ResponseWriter, r *http.Request, nonce string, body []byte, encoders []encoder) {
+	w.Header().Set("Content-Security-Policy", strings.ReplaceAll(s.policy, nonceToken, nonce))
+	if len(encoders) > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+		for _, e := range encoders {
+			if !acceptsEncoding(r, e.coding) {
+				continue
+			}
+			if data, err := e.encodeFast(body); err == nil {
+				w.Header().Set("Content-Encoding", e.coding)
+				body = data
+			}
+			break
+		}
+	}
+
+	// the body differs on every response, so there is nothing to revalidate against
+	w.Header().Set("Cache-Control", cacheNoCache)
+	writeBody(w, r, body)
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func join(directive string, sources []string) string {
+	return strings.Join(append([]string{directive}, sources...), " ")
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
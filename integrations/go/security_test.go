@@ -0,0 +1,55 @@
+package ora
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCSPConnectsToEveryDocument(t *testing.T) {
+	policy := defaultCSP(&Config{
+		OpenapiDocs: []DocRef{
+			{Name: "users", Url: "https://users.example.com/openapi.json"},
+			{Name: "billing", Url: "https://billing.example.com:8443/openapi.json"},
+			{Name: "local", Url: "/openapi.json"},
+		},
+		OidcIssuer: "https://idp.example.com",
+	}, nil)
+
+	var connect string
+	for _, directive := range strings.Split(policy, "; ") {
+		if strings.HasPrefix(directive, "connect-src ") {
+			connect = directive
+		}
+	}
+	want := "connect-src 'self' https://idp.example.com https://users.example.com https://billing.example.com:8443"
+	if connect != want {
+		t.Errorf("connect-src = %q, want %q", connect, want)
+	}
+}
+
+func TestShellEncodedFastPerResponse(t *testing.T) {
+	var precompressed, perResponse int
+	halve := func(n *int) func([]byte) ([]byte, error) {
+		return func(body []byte) ([]byte, error) {
+			*n++
+			return body[:len(body)/2], nil // only has to be smaller
+		}
+	}
+	h := NewWithConfig(&Config{OpenapiDocUrl: "/openapi.json"}, WithSecurityHeaders(), WithEncoder("x-test", halve(&precompressed), halve(&perResponse)))
+	built := precompressed
+
+	for range 3 {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", "x-test")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "x-test" {
+			t.Fatalf("Content-Encoding = %q, want x-test", w.Header().Get("Content-Encoding"))
+		}
+	}
+	if perResponse != 3 || precompressed != built {
+		t.Errorf("shell encoded %d times fast and %d times at construction level, want 3 and 0", perResponse, precompressed-built)
+	}
+}
@@ -0,0 +1,55 @@
+package ora
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+)
+
+const specName = "openapi"
+
+// newDocRoute returns the path under the basename and the handler serving the OpenAPI document
+// when it is served by the handler itself, either from a local spec or through the proxy
+func newDocRoute(o *options) (string, http.Handler, error) {
+	c := &o.config
+	spec := o.spec
+	if spec == nil && c.OpenapiDocFile != "" {
+		data, err := os.ReadFile(c.OpenapiDocFile)
+		if err != nil {
+			return "", nil, fmt.Errorf("ora: invalid config: OpenapiDocFile: %w", err)
+		}
+		spec = data
+	}
+
+	if spec != nil && o.specFilter != nil {
+		filtered, err := o.specFilter(spec)
+		if err != nil {
+			return "", nil, fmt.Errorf("ora: filter spec: %w", err)
+		}
+		spec = filtered
+	}
+
+	switch {
+	case spec != nil:
+		ext, contentType := specFormat(spec)
+		a, err := newAsset(contentType, cacheNoCache, spec, o.contentEncoders())
+		return "/" + specName + ext, a, err
+	case c.OpenapiDocUrl == "" && len(c.OpenapiDocs) == 0:
+		return "", nil, fmt.Errorf("ora: invalid config: one of OpenapiDocUrl, OpenapiDocs or OpenapiDocFile is required")
+	case c.OpenapiDocProxyPath != "" && c.OpenapiDocUrl == "":
+		return "", nil, fmt.Errorf("ora: invalid config: OpenapiDocUrl is required when OpenapiDocProxyPath is set")
+	case c.OpenapiDocProxyPath != "":
+		return path.Clean("/" + c.OpenapiDocProxyPath), &docProxy{url: c.OpenapiDocUrl, client: o.client, timeout: o.fetchTimeout, filter: o.specFilter, logger: o.logger}, nil
+	}
+	return "", nil, nil
+}
+
+// specFormat tells JSON from YAML documents by their content
+func specFormat(spec []byte) (ext, contentType string) {
+	if bytes.HasPrefix(bytes.TrimSpace(spec), []byte("{")) {
+		return ".json", "application/json"
+	}
+	return ".yaml", "application/yaml"
+}
@@ -0,0 +1,65 @@
+package ora
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"sync"
+)
+
+// modulePath is the path of this module, looked up in the build info of the binary embedding it
+const modulePath = "github.com/saltbo/openapi-rest-admin/integrations/go"
+
+// versionPath is where the handler serves its BuildInfo under the basename
+const versionPath = "/__ora/version"
+
+// BuildInfo identifies the build serving the admin, for telling deployments apart when triaging bugs
+type BuildInfo struct {
+	// Version is the version of this module, (devel) when built from a checkout of it
+	Version string `json:"version"`
+	// GoVersion, Revision, Time and Modified describe the binary, from its VCS stamps
+	GoVersion string `json:"goVersion,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// defaultBuildInfo is read once from the metadata the go command embeds in the binary
+var defaultBuildInfo = sync.OnceValue(func() BuildInfo {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{Version: "unknown"}
+	}
+
+	info := BuildInfo{Version: "unknown", GoVersion: bi.GoVersion}
+	if bi.Main.Path == modulePath {
+		info.Version = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == modulePath {
+			info.Version = dep.Version
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				info.Version = dep.Replace.Version
+			}
+		}
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+})
+
+// newVersionAsset serves info as JSON, it is revalidated like the shell since it changes on deploys
+func newVersionAsset(info *BuildInfo) (*asset, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	return newAsset("application/json", cacheNoCache, data, nil)
+}
@@ -0,0 +1,40 @@
+package ora
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestVersionEndpointIsOptIn(t *testing.T) {
+	cfg := &Config{Basename: "/admin", OpenapiDocUrl: "/openapi.json"}
+	info := BuildInfo{Version: "v1.2.3"}
+
+	off := NewWithConfig(cfg, WithBuildInfo(info))
+	if w := get(off, "/admin/__ora/version"); w.Code == http.StatusOK && w.Header().Get("Content-Type") == "application/json" {
+		t.Errorf("version served by default: %s", w.Body)
+	}
+	if build, _ := configOf(t, get(off, "/admin/").Body.String())["build"].(map[string]any); build["version"] != "v1.2.3" {
+		t.Errorf("build in the config = %v, want v1.2.3 even without the endpoint", build)
+	}
+
+	w := get(NewWithConfig(cfg, WithBuildInfo(info), WithVersionEndpoint(true)), "/admin/__ora/version")
+	var got BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); w.Code != http.StatusOK || err != nil || got != info {
+		t.Errorf("GET /admin/__ora/version = %d %s, want %+v", w.Code, w.Body, info)
+	}
+}
+
+func TestDefaultBuildInfoInConfig(t *testing.T) {
+	cfg := &Config{OpenapiDocUrl: "/openapi.json"}
+	build, _ := configOf(t, get(NewWithConfig(cfg), "/").Body.String())["build"].(map[string]any)
+	if len(build) != 1 || build["version"] != defaultBuildInfo().Version {
+		t.Errorf("build in the config = %v, want the module version only", build)
+	}
+
+	var got BuildInfo
+	w := get(NewWithConfig(cfg, WithVersionEndpoint(true)), "/__ora/version")
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got != defaultBuildInfo() {
+		t.Errorf("GET /__ora/version = %s, want the full build %+v", w.Body, defaultBuildInfo())
+	}
+}